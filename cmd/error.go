@@ -1,12 +1,13 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
 )
 
-// errorExit prints an error message and exits with a non-zero status
+// errorExit reports an error and exits with a non-zero status
 func errorExit(err error) {
-	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	newDiagnosticsHandler().Handle(diag.Diagnostic{Severity: diag.Error, Message: err.Error()})
 	os.Exit(1)
-}
\ No newline at end of file
+}
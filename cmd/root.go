@@ -1,11 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/cache"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/formatter"
 	"github.com/jishnusygal/terraform-docs-extended/pkg/processor"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/source"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +31,21 @@ var (
 	moduleName   string
 	moduleSource string
 	quiet        bool
+	noCache      bool
+	changedSince string
+	includes     []string
+	excludes     []string
+	noGitignore  bool
+	listModules  bool
+	inferSource  string
+	registry     string
+	tfvarsOpt    bool
+	toc          bool
+	anchors      bool
+	usageStyle   string
+	timeout      time.Duration
+	logFormat    string
+	failOn       string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -36,37 +61,224 @@ separated into "Required" and "Optional" sections.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate path
 		if _, err := os.Stat(modulePath); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Error: Module path does not exist: %s\n", modulePath)
-			os.Exit(1)
+			errorExit(fmt.Errorf("Module path does not exist: %s", modulePath))
 		}
 
 		// Validate output format
-		if outputFormat != "markdown" && outputFormat != "json" {
-			fmt.Fprintf(os.Stderr, "Error: Invalid output format: %s. Must be 'markdown' or 'json'\n", outputFormat)
-			os.Exit(1)
+		if !formatter.IsSupportedFormat(outputFormat) {
+			errorExit(fmt.Errorf("Invalid output format: %s. Must be one of: %s", outputFormat, strings.Join(formatter.SupportedFormats, ", ")))
+		}
+
+		// Validate diagnostics settings
+		failSeverity := diagnosticsSettings()
+
+		// Load the project configuration
+		cfg, err := config.Load(modulePath)
+		if err != nil {
+			errorExit(err)
+		}
+		opts := newOptions(cmd, cfg)
+
+		// Print the directories that would be processed without generating anything
+		if listModules {
+			modules, err := processor.SelectModules(context.Background(), opts)
+			if err != nil {
+				errorExit(err)
+			}
+			for _, module := range modules {
+				fmt.Println(module)
+			}
+			return
 		}
 
 		// Check if terraform-docs is installed
 		if !processor.IsTerraformDocsInstalled() {
-			fmt.Fprintf(os.Stderr, "Error: terraform-docs is not installed or not found in PATH\n")
-			os.Exit(1)
+			errorExit(fmt.Errorf("terraform-docs is not installed or not found in PATH"))
 		}
 
-		// Process directories based on recursive flag
-		var err error
-		if recursive {
-			err = processor.ProcessRecursively(modulePath, outputFormat, outputFile, moduleName, moduleSource, quiet)
-		} else {
-			err = processor.ProcessDirectory(modulePath, outputFormat, outputFile, moduleName, moduleSource, quiet)
-		}
+		// Stop cleanly on Ctrl-C; a second interrupt terminates immediately
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
 
-		// Handle any errors
+		// Process the selected modules and handle any errors
+		result, err := processor.Generate(ctx, opts)
 		if err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("interrupted: %v", err)
+			}
 			errorExit(err)
 		}
+
+		// Fail the run when diagnostics reach the --fail-on threshold
+		if result.Diagnostics.HasAtLeast(failSeverity) {
+			os.Exit(1)
+		}
 	},
 }
 
+// newOptions builds the processor options from the command line flags and
+// the project configuration. Flags given on the command line take precedence
+// over the configuration, which takes precedence over the flag defaults.
+func newOptions(cmd *cobra.Command, cfg config.Config) processor.Options {
+	opts := processor.Options{
+		Path:      modulePath,
+		Recursive: recursive,
+		// Patterns from the command line add to those from the configuration file
+		Discovery: processor.DiscoveryOptions{
+			Include:   append(cfg.Include, includes...),
+			Exclude:   append(cfg.Exclude, excludes...),
+			Gitignore: !noGitignore && config.Bool(cfg.Gitignore, true),
+		},
+		ChangedSince: changedSince,
+		Config:       cfg,
+		Overrides:    flagConfig(cmd),
+		Timeout:      timeout,
+		Stdout:       os.Stdout,
+		Diagnostics:  newDiagnosticsHandler(),
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("format") {
+		opts.Format = outputFormat
+	}
+	if flags.Changed("out") {
+		opts.Output = outputFile
+	}
+	if flags.Changed("name") {
+		opts.ModuleName = moduleName
+	}
+	if flags.Changed("source") {
+		opts.ModuleSource = moduleSource
+	}
+
+	// Reuse output rendered by previous runs unless disabled
+	if !noCache {
+		c, err := newCache()
+		if err != nil {
+			opts.Diagnostics.Handle(diag.Diagnostic{Severity: diag.Warning, Message: fmt.Sprintf("Caching disabled: %v", err)})
+		}
+		opts.Cache = c
+	}
+
+	return opts
+}
+
+// flagConfig returns the configuration settings given as flags that have no
+// field of their own in processor.Options
+func flagConfig(cmd *cobra.Command) config.Config {
+	var cfg config.Config
+	flags := cmd.Flags()
+	if flags.Changed("infer-source") {
+		cfg.Usage.InferSource = inferSource
+	}
+	if flags.Changed("registry") {
+		cfg.Usage.Registry = registry
+	}
+	if flags.Changed("usage-style") {
+		cfg.Usage.Style = usageStyle
+	}
+	if flags.Changed("tfvars-optional") {
+		cfg.Tfvars.Optional = &tfvarsOpt
+	}
+	if flags.Changed("toc") {
+		cfg.Markdown.TOC = &toc
+	}
+	if flags.Changed("anchors") {
+		cfg.Markdown.Anchors = &anchors
+	}
+	return cfg
+}
+
+// effectiveConfig returns the configuration a run with the current flags
+// would use for the module at modulePath: the built-in defaults, overridden by
+// the configuration files, overridden by the flags given on the command line
+func effectiveConfig(cmd *cobra.Command) (config.Config, error) {
+	cfg, err := config.Load(modulePath)
+	if err != nil {
+		return cfg, err
+	}
+
+	enabled, disabled := true, false
+	defaults := config.Config{
+		Format:    "markdown",
+		Gitignore: &enabled,
+		Usage: config.Usage{
+			Name:         "example",
+			ShowDefaults: &disabled,
+			InferSource:  source.KindNone,
+			Style:        formatter.UsageStyleModule,
+		},
+		Tfvars:   config.Tfvars{Optional: &enabled},
+		Markdown: config.Markdown{TOC: &disabled, Anchors: &disabled},
+	}
+
+	flags := cmd.Flags()
+	override := flagConfig(cmd)
+	if flags.Changed("format") {
+		override.Format = outputFormat
+	}
+	if flags.Changed("out") {
+		override.Output = outputFile
+	}
+	if flags.Changed("name") {
+		override.Usage.Name = moduleName
+	}
+	if flags.Changed("source") {
+		override.Usage.Source = moduleSource
+	}
+	if len(includes) > 0 {
+		override.Include = append(cfg.Include, includes...)
+	}
+	if len(excludes) > 0 {
+		override.Exclude = append(cfg.Exclude, excludes...)
+	}
+	if noGitignore {
+		override.Gitignore = &disabled
+	}
+
+	effective := defaults.Merge(cfg).Merge(override)
+
+	// Without inference every module gets the placeholder source
+	if effective.Usage.Source == "" && effective.Usage.InferSource == source.KindNone {
+		effective.Usage.Source = formatter.DefaultModuleSource
+	}
+
+	return effective, nil
+}
+
+// diagnosticsSettings validates --log-format and returns the severity given with --fail-on
+func diagnosticsSettings() diag.Severity {
+	if logFormat != "text" && logFormat != "json" {
+		invalid := logFormat
+		logFormat = "text"
+		errorExit(fmt.Errorf("Invalid log format: %s. Must be 'text' or 'json'", invalid))
+	}
+	// Progress messages are reported at info severity, so they cannot fail a run
+	failSeverity, err := diag.ParseSeverity(failOn)
+	if err != nil || failSeverity < diag.Warning {
+		errorExit(fmt.Errorf("Invalid fail-on severity: %s. Must be 'warning' or 'error'", failOn))
+	}
+	return failSeverity
+}
+
+// newDiagnosticsHandler prints diagnostics to stderr in the format chosen with
+// --log-format; --quiet limits the output to errors
+func newDiagnosticsHandler() diag.Handler {
+	minSeverity := diag.Info
+	if quiet {
+		minSeverity = diag.Error
+	}
+
+	if logFormat == "json" {
+		return diag.NewJSONPrinter(os.Stderr, minSeverity)
+	}
+	return diag.NewTextPrinter(os.Stderr, minSeverity, !color.NoColor)
+}
+
 // versionCmd displays version information
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -77,6 +289,99 @@ var versionCmd = &cobra.Command{
 	},
 }
 
+// cacheCmd groups the cache management commands
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of rendered documentation",
+}
+
+// cacheCleanCmd removes all cached output
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove all cached documentation",
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := newCache()
+		if err != nil {
+			errorExit(err)
+		}
+		if err := c.Clean(); err != nil {
+			errorExit(err)
+		}
+		if !quiet {
+			fmt.Printf("Cache cleaned: %s\n", c.Dir)
+		}
+	},
+}
+
+// configCmd groups the configuration commands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the " + config.FileName + " configuration",
+}
+
+// configPrintCmd prints the effective configuration
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration for the module path",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := effectiveConfig(cmd)
+		if err != nil {
+			errorExit(err)
+		}
+		out, err := cfg.Marshal()
+		if err != nil {
+			errorExit(err)
+		}
+		fmt.Print(out)
+	},
+}
+
+// examplesCmd groups the commands working on module examples
+var examplesCmd = &cobra.Command{
+	Use:   "examples",
+	Short: "Work with the examples shipped with modules",
+}
+
+// examplesValidateCmd checks examples against the module interface
+var examplesValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that module examples match the inputs of the modules they call",
+	Long: `Checks every module block in a module's examples directory whose source points
+at the module, reporting arguments the module does not declare, required inputs
+that are not set and literal values whose type is incompatible with the input.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(modulePath); os.IsNotExist(err) {
+			errorExit(fmt.Errorf("Module path does not exist: %s", modulePath))
+		}
+		failSeverity := diagnosticsSettings()
+
+		cfg, err := config.Load(modulePath)
+		if err != nil {
+			errorExit(err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		diags, err := processor.ValidateExamples(ctx, newOptions(cmd, cfg))
+		if err != nil {
+			errorExit(err)
+		}
+		if diags.HasAtLeast(failSeverity) {
+			os.Exit(1)
+		}
+	},
+}
+
+// newCache returns the cache in the user's cache directory
+func newCache() (*cache.Cache, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir, Version), nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -86,15 +391,46 @@ func Execute() {
 }
 
 func init() {
-	// Add version command
+	// Add version and cache commands
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(examplesCmd)
+	examplesCmd.AddCommand(examplesValidateCmd)
 
 	// Add command line flags
-	rootCmd.Flags().StringVarP(&modulePath, "path", "p", ".", "Path to the Terraform module directory")
-	rootCmd.Flags().StringVarP(&outputFile, "out", "o", "", "Output file path (defaults to stdout)")
-	rootCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Process directories recursively")
-	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "markdown", "Output format (markdown or json)")
-	rootCmd.Flags().StringVarP(&moduleName, "name", "n", "example", "Module name to use in the usage example")
-	rootCmd.Flags().StringVarP(&moduleSource, "source", "s", "path/to/module", "Module source to use in the usage example")
-	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational output")
+	rootCmd.PersistentFlags().StringVarP(&modulePath, "path", "p", ".", "Path to the Terraform module directory")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational output and warnings")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of progress messages and diagnostics on stderr (text or json)")
+	rootCmd.Flags().BoolVar(&listModules, "list-modules", false, "Print the module directories that would be processed and exit")
+	rootCmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "Maximum time the terraform-docs runs for each module may take together (0 for no limit)")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "Regenerate every module instead of reusing cached output")
+
+	// Flags selecting and checking modules, also accepted by "examples validate"
+	for _, c := range []*cobra.Command{rootCmd, examplesValidateCmd} {
+		c.Flags().BoolVarP(&recursive, "recursive", "r", false, "Process directories recursively")
+		c.Flags().StringVar(&changedSince, "changed-since", "", "Only process modules changed relative to this git ref")
+		c.Flags().StringVar(&failOn, "fail-on", "error", "Exit with a non-zero status when a diagnostic of this severity or worse is reported (warning or error)")
+	}
+	for _, c := range []*cobra.Command{rootCmd, configPrintCmd, examplesValidateCmd} {
+		c.Flags().StringSliceVar(&includes, "include", nil, "Glob of directories to process in recursive mode (repeatable)")
+		c.Flags().StringSliceVar(&excludes, "exclude", nil, "Glob of directories to skip in recursive mode (repeatable)")
+		c.Flags().BoolVar(&noGitignore, "no-gitignore", false, "Do not skip directories ignored by .gitignore")
+	}
+
+	// Flags that override the configuration file, also accepted by "config print"
+	for _, c := range []*cobra.Command{rootCmd, configPrintCmd} {
+		c.Flags().StringVarP(&outputFile, "out", "o", "", "Output file path or template, e.g. \"docs/{{.ModuleName}}.{{.Ext}}\" (defaults to stdout, or \""+processor.DefaultOutputTemplate+"\" with --recursive)")
+		c.Flags().StringVarP(&outputFormat, "format", "f", "markdown", "Output format ("+strings.Join(formatter.SupportedFormats, ", ")+")")
+		c.Flags().StringVarP(&moduleName, "name", "n", "example", "Module name to use in the usage example")
+		c.Flags().StringVarP(&moduleSource, "source", "s", formatter.DefaultModuleSource, "Module source to use in the usage example")
+		c.Flags().StringVar(&inferSource, "infer-source", source.KindNone, "Infer each module's source when --source is not given ("+strings.Join(source.Kinds, ", ")+")")
+		c.Flags().StringVar(&registry, "registry", "", "Registry namespace for inferred registry sources, e.g. app.terraform.io/org")
+		c.Flags().StringVar(&usageStyle, "usage-style", formatter.UsageStyleModule, "Shape of the usage examples ("+strings.Join(formatter.UsageStyles, ", ")+")")
+		c.Flags().BoolVar(&tfvarsOpt, "tfvars-optional", true, "Include optional inputs, set to their defaults, in the tfvars format")
+		c.Flags().BoolVar(&toc, "toc", false, "Insert a table of contents after the header of markdown output")
+		c.Flags().BoolVar(&anchors, "anchors", false, "Link the inputs in markdown usage examples to the inputs table")
+	}
 }
@@ -5,5 +5,5 @@ go 1.16
 require (
 	github.com/fatih/color v1.13.0
 	github.com/spf13/cobra v1.4.0
-	github.com/stretchr/testify v1.7.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
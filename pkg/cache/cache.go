@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// DefaultDir returns the cache location in the user's cache directory, such as
+// ~/.cache/terraform-docs-extended on Linux
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user cache directory: %v", err)
+	}
+	return filepath.Join(dir, "terraform-docs-extended"), nil
+}
+
+// Cache stores rendered documentation on disk so unchanged modules can be
+// skipped on subsequent runs
+type Cache struct {
+	Dir     string
+	Version string
+}
+
+// New creates a cache rooted at dir for the given tool version
+func New(dir string, version string) *Cache {
+	return &Cache{
+		Dir:     dir,
+		Version: version,
+	}
+}
+
+// Key derives the cache key for a module from the content of its .tf files,
+// its terraform-docs configuration, the tool version and any render parameters
+func (c *Cache) Key(modulePath string, params ...string) (string, error) {
+	moduleHash, err := terraform.HashModule(modulePath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "version:%s\n", c.Version)
+	fmt.Fprintf(h, "module:%s\n", moduleHash)
+	for _, param := range params {
+		fmt.Fprintf(h, "param:%s\n", param)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get returns the cached output for key, if present
+func (c *Cache) Get(key string) (string, bool) {
+	content, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// Put stores the rendered output for key. The entry is written to a temporary
+// file that is renamed into place, so an interrupted or concurrent run never
+// leaves a partial entry behind.
+func (c *Cache) Put(key string, content string) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	f, err := ioutil.TempFile(c.Dir, "."+key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	return nil
+}
+
+// Clean removes every entry from the cache
+func (c *Cache) Clean() error {
+	if err := os.RemoveAll(c.Dir); err != nil {
+		return fmt.Errorf("failed to remove cache directory: %v", err)
+	}
+	return nil
+}
+
+// path returns the file holding the entry for key
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key)
+}
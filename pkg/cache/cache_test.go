@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	module := filepath.Join(dir, "module")
+	if err := os.Mkdir(module, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mainTf := filepath.Join(module, "main.tf")
+	if err := ioutil.WriteFile(mainTf, []byte(`variable "a" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(filepath.Join(dir, "cache"), "1.0.0")
+	key, err := c.Key(module, "markdown")
+	if err != nil {
+		t.Fatalf("Key failed: %v", err)
+	}
+
+	if _, ok := c.Get(key); ok {
+		t.Errorf("Expected empty cache to miss")
+	}
+	if err := c.Put(key, "# Docs"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if content, ok := c.Get(key); !ok || content != "# Docs" {
+		t.Errorf("Expected cached content '# Docs', got '%s' (hit=%v)", content, ok)
+	}
+	if entries, _ := ioutil.ReadDir(c.Dir); len(entries) != 1 {
+		t.Errorf("Expected only the cache entry in %s, got %d files", c.Dir, len(entries))
+	}
+
+	// Render parameters and tool version are part of the key
+	if other, _ := c.Key(module, "json"); other == key {
+		t.Errorf("Expected different formats to produce different keys")
+	}
+	if other, _ := New(c.Dir, "2.0.0").Key(module, "markdown"); other == key {
+		t.Errorf("Expected different versions to produce different keys")
+	}
+
+	// Editing the module invalidates the entry
+	if err := ioutil.WriteFile(mainTf, []byte(`variable "b" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := c.Key(module, "markdown"); changed == key {
+		t.Errorf("Expected key to change when the module changes")
+	}
+
+	if err := c.Clean(); err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if _, ok := c.Get(key); ok {
+		t.Errorf("Expected cache to be empty after Clean")
+	}
+}
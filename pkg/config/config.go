@@ -0,0 +1,272 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the terraform-docs-extended configuration file
+const FileName = ".terraform-docs-extended.yml"
+
+// Config holds the settings read from a terraform-docs-extended configuration file.
+// Unset fields leave the built-in defaults, or the settings of a parent
+// configuration, in place.
+type Config struct {
+	// Format is the output format and Output the output path template
+	Format string `yaml:"format,omitempty"`
+	Output string `yaml:"output,omitempty"`
+
+	// Include and Exclude select the directories processed in recursive mode
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Gitignore controls whether directories ignored by git are skipped
+	Gitignore *bool `yaml:"gitignore,omitempty"`
+
+	// Sections lists the sections of the documentation to include
+	Sections []string `yaml:"sections,omitempty"`
+
+	// InputGroups assigns inputs to groups, taking precedence over the
+	// groups named in the module
+	InputGroups []InputGroup `yaml:"input_groups,omitempty"`
+
+	// Usage configures the generated usage example
+	Usage Usage `yaml:"usage,omitempty"`
+
+	// Tfvars configures the tfvars format
+	Tfvars Tfvars `yaml:"tfvars,omitempty"`
+
+	// Markdown configures the markdown format
+	Markdown Markdown `yaml:"markdown,omitempty"`
+}
+
+// Usage holds the settings for the usage example
+type Usage struct {
+	// Name and Source are templates for the module name and source, e.g.
+	// "git::https://example.com/infra.git//{{.RelDir}}"
+	Name   string `yaml:"name,omitempty"`
+	Source string `yaml:"source,omitempty"`
+	// Version is the version constraint shown with the source
+	Version string `yaml:"version,omitempty"`
+
+	// InferSource infers the source of each module when Source is not set:
+	// "none", "auto", "git", "registry" or "path"
+	InferSource string `yaml:"infer_source,omitempty"`
+	// Registry is the registry namespace used for registry sources, e.g.
+	// "app.terraform.io/org", and Provider the registry provider
+	Registry string `yaml:"registry,omitempty"`
+	Provider string `yaml:"provider,omitempty"`
+
+	// ShowDefaults shows the default value of optional inputs instead of their type
+	ShowDefaults *bool `yaml:"show_defaults,omitempty"`
+	// Style is the shape of the usage examples: "module" or "terragrunt"
+	Style string `yaml:"style,omitempty"`
+
+	// ExampleValues maps input names to the HCL expression used as their value
+	ExampleValues map[string]string `yaml:"example_values,omitempty"`
+
+	// Examples lists the generated examples to show: "default", "minimal"
+	// and "complete". Only the default example is shown when it is unset.
+	Examples []string `yaml:"examples,omitempty"`
+	// Scenarios are additional examples setting the given optional inputs
+	Scenarios []Scenario `yaml:"scenarios,omitempty"`
+	// ExamplesDir is a directory of the module whose subdirectories hold
+	// example configurations to embed, e.g. "examples"
+	ExamplesDir string `yaml:"examples_dir,omitempty"`
+}
+
+// Tfvars holds the settings for the tfvars format
+type Tfvars struct {
+	// Optional includes the optional inputs, set to their defaults
+	Optional *bool `yaml:"optional,omitempty"`
+}
+
+// Markdown holds the settings for the markdown format
+type Markdown struct {
+	// TOC inserts a table of contents after the header
+	TOC *bool `yaml:"toc,omitempty"`
+	// Anchors links the inputs set in the usage examples to the inputs table
+	Anchors *bool `yaml:"anchors,omitempty"`
+}
+
+// InputGroup assigns the inputs matching Inputs, names or glob patterns
+// such as "subnet_*", to the group Name
+type InputGroup struct {
+	Name   string   `yaml:"name"`
+	Inputs []string `yaml:"inputs"`
+}
+
+// Scenario is a named usage example declared in the configuration
+type Scenario struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Values      map[string]string `yaml:"values,omitempty"`
+}
+
+// Load reads the configuration files found in dir and each of its parent
+// directories and merges them, so that a file closer to dir overrides the
+// settings of the files above it. No file yields an empty configuration.
+func Load(dir string) (Config, error) {
+	var cfg Config
+
+	paths, err := Find(dir)
+	if err != nil {
+		return cfg, err
+	}
+
+	// Apply the outermost file first
+	for i := len(paths) - 1; i >= 0; i-- {
+		override, err := LoadFile(paths[i])
+		if err != nil {
+			return cfg, err
+		}
+		cfg = cfg.Merge(override)
+	}
+
+	return cfg, nil
+}
+
+// Find returns the paths of the configuration files in dir and each of its
+// parent directories, nearest first
+func Find(dir string) ([]string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for {
+		path := filepath.Join(abs, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			paths = append(paths, path)
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return paths, nil
+		}
+		abs = parent
+	}
+}
+
+// LoadFile reads the configuration file at path
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Merge returns c with every field set in override replacing the value in c.
+// Example values are merged per input.
+func (c Config) Merge(override Config) Config {
+	result := c
+
+	if override.Format != "" {
+		result.Format = override.Format
+	}
+	if override.Output != "" {
+		result.Output = override.Output
+	}
+	if override.Include != nil {
+		result.Include = override.Include
+	}
+	if override.Exclude != nil {
+		result.Exclude = override.Exclude
+	}
+	if override.Gitignore != nil {
+		result.Gitignore = override.Gitignore
+	}
+	if override.Sections != nil {
+		result.Sections = override.Sections
+	}
+	if override.InputGroups != nil {
+		result.InputGroups = override.InputGroups
+	}
+
+	if override.Usage.Name != "" {
+		result.Usage.Name = override.Usage.Name
+	}
+	if override.Usage.Source != "" {
+		result.Usage.Source = override.Usage.Source
+	}
+	if override.Usage.Version != "" {
+		result.Usage.Version = override.Usage.Version
+	}
+	if override.Usage.InferSource != "" {
+		result.Usage.InferSource = override.Usage.InferSource
+	}
+	if override.Usage.Registry != "" {
+		result.Usage.Registry = override.Usage.Registry
+	}
+	if override.Usage.Provider != "" {
+		result.Usage.Provider = override.Usage.Provider
+	}
+	if override.Usage.ShowDefaults != nil {
+		result.Usage.ShowDefaults = override.Usage.ShowDefaults
+	}
+	if override.Usage.Style != "" {
+		result.Usage.Style = override.Usage.Style
+	}
+	if override.Usage.Examples != nil {
+		result.Usage.Examples = override.Usage.Examples
+	}
+	if override.Usage.Scenarios != nil {
+		result.Usage.Scenarios = override.Usage.Scenarios
+	}
+	if override.Usage.ExamplesDir != "" {
+		result.Usage.ExamplesDir = override.Usage.ExamplesDir
+	}
+	if override.Usage.ExampleValues != nil {
+		values := make(map[string]string, len(c.Usage.ExampleValues)+len(override.Usage.ExampleValues))
+		for name, value := range c.Usage.ExampleValues {
+			values[name] = value
+		}
+		for name, value := range override.Usage.ExampleValues {
+			values[name] = value
+		}
+		result.Usage.ExampleValues = values
+	}
+
+	if override.Tfvars.Optional != nil {
+		result.Tfvars.Optional = override.Tfvars.Optional
+	}
+
+	if override.Markdown.TOC != nil {
+		result.Markdown.TOC = override.Markdown.TOC
+	}
+	if override.Markdown.Anchors != nil {
+		result.Markdown.Anchors = override.Markdown.Anchors
+	}
+
+	return result
+}
+
+// Bool returns the value of an optional boolean setting, or def when it is unset
+func Bool(value *bool, def bool) bool {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// Marshal encodes the configuration as YAML
+func (c Config) Marshal() (string, error) {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode configuration: %v", err)
+	}
+	return string(out), nil
+}
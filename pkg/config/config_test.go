@@ -0,0 +1,93 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMergesFilesUpTheTree(t *testing.T) {
+	root, err := ioutil.TempDir("", "tfdocs-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	module := filepath.Join(root, "modules", "vpc")
+	if err := os.MkdirAll(module, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	project := `
+format: json
+exclude: [examples]
+usage:
+  source: "git::https://example.com/infra.git//{{.RelDir}}"
+  example_values:
+    region: '"eu-west-1"'
+    name: '"main"'
+`
+	override := `
+sections: [inputs, usage]
+usage:
+  show_defaults: true
+  example_values:
+    name: '"vpc"'
+`
+	if err := ioutil.WriteFile(filepath.Join(root, FileName), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(module, FileName), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(module)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Format != "json" {
+		t.Errorf("Expected the format from the project file, got %q", cfg.Format)
+	}
+	if !reflect.DeepEqual(cfg.Exclude, []string{"examples"}) {
+		t.Errorf("Expected excludes from the project file, got %v", cfg.Exclude)
+	}
+	if !reflect.DeepEqual(cfg.Sections, []string{"inputs", "usage"}) {
+		t.Errorf("Expected sections from the module file, got %v", cfg.Sections)
+	}
+	if !Bool(cfg.Usage.ShowDefaults, false) {
+		t.Errorf("Expected show_defaults from the module file")
+	}
+	expected := map[string]string{"region": `"eu-west-1"`, "name": `"vpc"`}
+	if !reflect.DeepEqual(cfg.Usage.ExampleValues, expected) {
+		t.Errorf("Expected example values %v, got %v", expected, cfg.Usage.ExampleValues)
+	}
+
+	// A directory outside the tree has no configuration
+	other, err := ioutil.TempDir("", "tfdocs-config-none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(other)
+	if paths, err := Find(other); err != nil || len(paths) != 0 {
+		t.Errorf("Expected no configuration files, got %v (%v)", paths, err)
+	}
+}
+
+func TestLoadFileRejectsInvalidYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-config-invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, FileName)
+	if err := ioutil.WriteFile(path, []byte("format: [json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Errorf("Expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,181 @@
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// Severity ranks how serious a diagnostic is
+type Severity int
+
+// Severities in increasing order of seriousness
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+// String returns the lower-case name of the severity
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// MarshalJSON encodes the severity by name
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// ParseSeverity converts a severity name such as "warning" to a Severity
+func ParseSeverity(name string) (Severity, error) {
+	for _, s := range []Severity{Info, Warning, Error} {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return Info, fmt.Errorf("unknown severity %q: must be one of info, warning, error", name)
+}
+
+// Diagnostic is a single message produced while generating documentation
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Module   string   `json:"module,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Location describes where the diagnostic applies, as file:line or module path
+func (d Diagnostic) Location() string {
+	switch {
+	case d.File != "" && d.Line > 0:
+		return fmt.Sprintf("%s:%d", d.File, d.Line)
+	case d.File != "":
+		return d.File
+	default:
+		return d.Module
+	}
+}
+
+// Diagnostics is a list of diagnostics
+type Diagnostics []Diagnostic
+
+// HasAtLeast reports whether any diagnostic is at least as serious as severity
+func (ds Diagnostics) HasAtLeast(severity Severity) bool {
+	for _, d := range ds {
+		if d.Severity >= severity {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the diagnostics at least as serious as severity
+func (ds Diagnostics) Filter(severity Severity) Diagnostics {
+	var result Diagnostics
+	for _, d := range ds {
+		if d.Severity >= severity {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// Handler receives diagnostics as they are produced
+type Handler interface {
+	Handle(d Diagnostic)
+}
+
+// TextPrinter writes human-readable diagnostics, optionally coloured by severity
+type TextPrinter struct {
+	mu          sync.Mutex
+	w           io.Writer
+	minSeverity Severity
+	colors      map[Severity]*color.Color
+}
+
+// NewTextPrinter creates a printer writing diagnostics of at least minSeverity to w
+func NewTextPrinter(w io.Writer, minSeverity Severity, useColor bool) *TextPrinter {
+	p := &TextPrinter{
+		w:           w,
+		minSeverity: minSeverity,
+		colors: map[Severity]*color.Color{
+			Warning: color.New(color.FgYellow),
+			Error:   color.New(color.FgRed, color.Bold),
+		},
+	}
+	for _, c := range p.colors {
+		if useColor {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+	return p
+}
+
+// Handle prints a diagnostic as "Warning: file:line: message"; informational
+// messages are printed as they are
+func (p *TextPrinter) Handle(d Diagnostic) {
+	if d.Severity < p.minSeverity {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if d.Severity == Info {
+		fmt.Fprintln(p.w, d.Message)
+		return
+	}
+
+	name := d.Severity.String()
+	prefix := strings.ToUpper(name[:1]) + name[1:] + ":"
+	if c, ok := p.colors[d.Severity]; ok {
+		prefix = c.Sprint(prefix)
+	}
+	if location := d.Location(); location != "" {
+		fmt.Fprintf(p.w, "%s %s: %s\n", prefix, location, d.Message)
+	} else {
+		fmt.Fprintf(p.w, "%s %s\n", prefix, d.Message)
+	}
+}
+
+// JSONPrinter writes one JSON object per diagnostic
+type JSONPrinter struct {
+	mu          sync.Mutex
+	enc         *json.Encoder
+	minSeverity Severity
+}
+
+// NewJSONPrinter creates a printer writing diagnostics of at least minSeverity to w
+func NewJSONPrinter(w io.Writer, minSeverity Severity) *JSONPrinter {
+	return &JSONPrinter{
+		enc:         json.NewEncoder(w),
+		minSeverity: minSeverity,
+	}
+}
+
+// Handle writes the diagnostic as a single line of JSON
+func (p *JSONPrinter) Handle(d Diagnostic) {
+	if d.Severity < p.minSeverity {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enc.Encode(d)
+}
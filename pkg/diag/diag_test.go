@@ -0,0 +1,59 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTextPrinter(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewTextPrinter(&buf, Info, false)
+
+	p.Handle(Diagnostic{Severity: Info, Message: "Processing module: vpc"})
+	p.Handle(Diagnostic{Severity: Warning, Module: "modules/vpc", Message: "terraform-docs timed out"})
+	p.Handle(Diagnostic{Severity: Error, Module: "modules/vpc", File: "modules/vpc/main.tf", Line: 12, Message: "bad block"})
+
+	expected := "Processing module: vpc\n" +
+		"Warning: modules/vpc: terraform-docs timed out\n" +
+		"Error: modules/vpc/main.tf:12: bad block\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestJSONPrinterMinSeverity(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewJSONPrinter(&buf, Error)
+
+	p.Handle(Diagnostic{Severity: Warning, Message: "ignored"})
+	p.Handle(Diagnostic{Severity: Error, Module: "modules/vpc", Message: "failed"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected a single JSON line, got %q", buf.String())
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decoded["severity"] != "error" || decoded["module"] != "modules/vpc" || decoded["message"] != "failed" {
+		t.Errorf("Unexpected JSON diagnostic: %v", decoded)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("Warning"); err != nil || s != Warning {
+		t.Errorf("Expected Warning, got %v (%v)", s, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Errorf("Expected an error for an unknown severity")
+	}
+
+	diags := Diagnostics{{Severity: Info}, {Severity: Warning}}
+	if !diags.HasAtLeast(Warning) || diags.HasAtLeast(Error) {
+		t.Errorf("Unexpected HasAtLeast results for %v", diags)
+	}
+}
@@ -0,0 +1,319 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GenerateAsciiDoc generates AsciiDoc documentation. The module sections are
+// rendered as tables from the structured terraform-docs output; without it only
+// the inputs known from parsing the module are listed.
+func GenerateAsciiDoc(module Module, moduleSource string, opts Options) string {
+	usage, embedded := opts.usageExamples()
+
+	formatter := NewUsageFormatter(module.Variables, module.Name, moduleSource)
+	formatter.ShowDefaults = opts.ShowDefaults && !opts.OmitDefault
+	formatter.ExampleValues = opts.ExampleValues
+	formatter.SortBy = opts.SortBy
+	formatter.Version = opts.ModuleVersion
+	formatter.Examples = usage
+	formatter.Style = opts.UsageStyle
+	formatter.Groups = opts.InputGroups
+
+	// Render the sections in order; the table of contents is filled in once
+	// the sections after it are known
+	var parts []string
+	toc := -1
+	for _, name := range opts.order() {
+		var sb strings.Builder
+
+		switch name {
+		case SectionHeader:
+			// Asciidoctor accepts the markdown-style headings of terraform-docs headers
+			if module.Header != "" {
+				sb.WriteString(module.Header)
+				sb.WriteString("\n\n")
+			}
+
+		case SectionTOC:
+			if opts.showsTOC() && toc < 0 {
+				toc = len(parts)
+			}
+
+		case SectionRequirements:
+			if module.Requirements != nil {
+				writeAsciiDocTable(&sb, "Requirements", []string{"Name", "Version"}, tableRows(module.Requirements, func(row map[string]interface{}) []string {
+					return []string{asciiDocText(stringField(row, "name")), asciiDocCode(stringField(row, "version"))}
+				}))
+			}
+
+		case SectionProviders:
+			if module.Providers != nil {
+				writeAsciiDocTable(&sb, "Providers", []string{"Name", "Version"}, tableRows(module.Providers, func(row map[string]interface{}) []string {
+					name := stringField(row, "name")
+					if alias := stringField(row, "alias"); alias != "" {
+						name += "." + alias
+					}
+					return []string{asciiDocText(name), asciiDocCode(stringField(row, "version"))}
+				}))
+			}
+
+		case SectionModules:
+			if module.Modules != nil {
+				writeAsciiDocTable(&sb, "Modules", []string{"Name", "Source", "Version"}, tableRows(module.Modules, func(row map[string]interface{}) []string {
+					return []string{
+						asciiDocText(stringField(row, "name")),
+						asciiDocCode(stringField(row, "source")),
+						asciiDocCode(stringField(row, "version")),
+					}
+				}))
+			}
+
+		case SectionResources:
+			if module.Resources != nil {
+				writeAsciiDocTable(&sb, "Resources", []string{"Name", "Type"}, tableRows(module.Resources, func(row map[string]interface{}) []string {
+					name := stringField(row, "type") + "." + stringField(row, "name")
+					kind := "resource"
+					if stringField(row, "mode") == "data" {
+						name = "data." + name
+						kind = "data source"
+					}
+					return []string{asciiDocCode(name), kind}
+				}))
+			}
+
+		case SectionInputs:
+			// Inputs come from the merged variables, so they are shown with or without terraform-docs
+			columns := []string{"Name", "Description", "Type"}
+			if !opts.OmitDefault {
+				columns = append(columns, "Default")
+			}
+			if !opts.OmitRequired {
+				columns = append(columns, "Required")
+			}
+
+			inputRows := func(variables []Variable) [][]string {
+				var rows [][]string
+				for _, v := range variables {
+					row := []string{asciiDocText(v.Name), asciiDocText(v.Description), asciiDocCode(v.Type)}
+					if !opts.OmitDefault {
+						defaultValue := "n/a"
+						if !v.Required {
+							defaultValue = asciiDocCode(formatHCLValue(v.Default))
+						}
+						row = append(row, defaultValue)
+					}
+					if !opts.OmitRequired {
+						required := "yes"
+						if !v.Required {
+							required = "no"
+						}
+						row = append(row, required)
+					}
+					rows = append(rows, row)
+				}
+				return rows
+			}
+
+			groups := groupVariables(sortVariables(module.Variables, opts.SortBy), opts.InputGroups)
+			if len(groups) == 1 && groups[0].Title == "" {
+				writeAsciiDocTable(&sb, "Inputs", columns, inputRows(groups[0].Variables))
+				break
+			}
+			// One table per group
+			sb.WriteString("== Inputs\n\n")
+			for _, group := range groups {
+				sb.WriteString(fmt.Sprintf("=== %s\n\n", group.Title))
+				writeAsciiDocRows(&sb, columns, inputRows(group.Variables))
+			}
+
+		case SectionOutputs:
+			if module.Outputs != nil {
+				writeAsciiDocTable(&sb, "Outputs", []string{"Name", "Description"}, tableRows(module.Outputs, func(row map[string]interface{}) []string {
+					return []string{asciiDocText(stringField(row, "name")), asciiDocText(stringField(row, "description"))}
+				}))
+			}
+
+		case SectionUsage:
+			sb.WriteString(formatter.FormatAsciiDoc())
+
+		case SectionExamples:
+			if len(embedded) > 0 {
+				sb.WriteString(formatter.formatAsciiDocExamples("Examples", embedded))
+			}
+
+		case SectionFooter:
+			if module.Footer != "" {
+				sb.WriteString(module.Footer)
+				sb.WriteString("\n")
+			}
+		}
+
+		parts = append(parts, sb.String())
+	}
+
+	if toc >= 0 {
+		parts[toc] = formatAsciiDocContents(strings.Join(parts[:toc], ""), strings.Join(parts[toc+1:], ""))
+	}
+
+	return strings.Join(parts, "")
+}
+
+// FormatAsciiDoc generates the Usage section in AsciiDoc format
+func (f *UsageFormatter) FormatAsciiDoc() string {
+	// A single unnamed listing unless named examples are configured
+	if len(f.Examples) == 0 {
+		var sb strings.Builder
+		sb.WriteString("== Usage\n\n")
+		writeAsciiDocListing(&sb, f.ExampleCode(Example{Kind: ExampleDefault}))
+		return sb.String()
+	}
+
+	return f.formatAsciiDocExamples("Usage", f.Examples)
+}
+
+// formatAsciiDocExamples renders a section holding the given named examples
+func (f *UsageFormatter) formatAsciiDocExamples(title string, examples []Example) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("== %s\n\n", title))
+	for _, example := range examples {
+		sb.WriteString(fmt.Sprintf("=== %s\n\n", example.Name))
+		if example.Description != "" {
+			sb.WriteString(example.Description)
+			sb.WriteString("\n\n")
+		}
+		writeAsciiDocListing(&sb, f.ExampleCode(example))
+	}
+
+	return sb.String()
+}
+
+var (
+	asciiDocHeadingRegex = regexp.MustCompile(`^(={1,6}|#{1,6}) +(.+?)\s*$`)
+	asciiDocIDRegex      = regexp.MustCompile(`[^\p{L}\p{N}_ .-]`)
+	asciiDocSepRegex     = regexp.MustCompile(`[ .-]+`)
+)
+
+// formatAsciiDocContents lists the level-one and level-two sections of body
+// as cross references. The sections of before are counted so that repeated
+// titles get the IDs Asciidoctor assigns.
+func formatAsciiDocContents(before string, body string) string {
+	ids := make(map[string]int)
+	id := func(title string) string {
+		base := strings.ToLower(asciiDocIDRegex.ReplaceAllString(title, ""))
+		base = "_" + strings.TrimSuffix(asciiDocSepRegex.ReplaceAllString(base, "_"), "_")
+		ids[base]++
+		if n := ids[base]; n > 1 {
+			return fmt.Sprintf("%s_%d", base, n)
+		}
+		return base
+	}
+
+	headings := func(doc string, visit func(level int, title string)) {
+		listing := false
+		for _, line := range strings.Split(doc, "\n") {
+			if line == "----" || strings.HasPrefix(line, "```") {
+				listing = !listing
+				continue
+			}
+			if match := asciiDocHeadingRegex.FindStringSubmatch(line); match != nil && !listing {
+				visit(len(match[1]), match[2])
+			}
+		}
+	}
+
+	headings(before, func(level int, title string) { id(title) })
+	id("Contents")
+
+	var sb strings.Builder
+	headings(body, func(level int, title string) {
+		ref := id(title)
+		if level == 2 || level == 3 {
+			sb.WriteString(fmt.Sprintf("%s <<%s,%s>>\n", strings.Repeat("*", level-1), ref, title))
+		}
+	})
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "== Contents\n\n" + sb.String() + "\n"
+}
+
+// writeAsciiDocListing writes HCL code as a source listing
+func writeAsciiDocListing(sb *strings.Builder, code string) {
+	sb.WriteString("[source,hcl]\n----\n")
+	sb.WriteString(code)
+	if !strings.HasSuffix(code, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("----\n\n")
+}
+
+// writeAsciiDocTable writes a level-one section holding a table, or a note
+// that the section is empty
+func writeAsciiDocTable(sb *strings.Builder, title string, columns []string, rows [][]string) {
+	sb.WriteString(fmt.Sprintf("== %s\n\n", title))
+
+	if len(rows) == 0 {
+		sb.WriteString(fmt.Sprintf("No %s.\n\n", strings.ToLower(title)))
+		return
+	}
+	writeAsciiDocRows(sb, columns, rows)
+}
+
+// writeAsciiDocRows writes a table with a header row
+func writeAsciiDocRows(sb *strings.Builder, columns []string, rows [][]string) {
+	sb.WriteString(fmt.Sprintf("[cols=\"%s\",options=\"header\"]\n", strings.TrimSuffix(strings.Repeat("1,", len(columns)), ",")))
+	sb.WriteString("|===\n")
+	sb.WriteString("|" + strings.Join(columns, " |") + "\n")
+	for _, row := range rows {
+		sb.WriteString("\n")
+		for _, cell := range row {
+			sb.WriteString("|" + cell + "\n")
+		}
+	}
+	sb.WriteString("|===\n\n")
+}
+
+// tableRows converts a list from the terraform-docs JSON document into table
+// rows, ordered by their first cell
+func tableRows(section interface{}, row func(map[string]interface{}) []string) [][]string {
+	items, _ := section.([]interface{})
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		if fields, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, row(fields))
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+
+	return rows
+}
+
+// stringField returns a string field of a terraform-docs JSON object, or an
+// empty string when it is missing
+func stringField(fields map[string]interface{}, name string) string {
+	value, _ := fields[name].(string)
+	return value
+}
+
+// asciiDocText escapes text for a table cell
+func asciiDocText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "n/a"
+	}
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// asciiDocCode renders a value as literal monospace text in a table cell
+func asciiDocCode(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "n/a"
+	}
+	return "`+" + strings.ReplaceAll(s, "|", "\\|") + "+`"
+}
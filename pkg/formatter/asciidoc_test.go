@@ -0,0 +1,90 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAsciiDoc(t *testing.T) {
+	module := Module{
+		Name:   "network",
+		Header: "# Network",
+		Footer: "== License",
+		Variables: map[string]Variable{
+			"name":  {Name: "name", Type: "string", Description: "Name | label", Required: true},
+			"cidrs": {Name: "cidrs", Type: "list(string)", Default: []interface{}{"10.0.0.0/16"}},
+		},
+		Requirements: []interface{}{
+			map[string]interface{}{"name": "terraform", "version": ">= 1.0"},
+		},
+		Providers: []interface{}{
+			map[string]interface{}{"name": "aws", "alias": "east"},
+		},
+		Modules: []interface{}{},
+		Resources: []interface{}{
+			map[string]interface{}{"type": "aws_vpc", "name": "this", "mode": "managed"},
+			map[string]interface{}{"type": "aws_region", "name": "current", "mode": "data"},
+		},
+		Outputs: []interface{}{
+			map[string]interface{}{"name": "id", "description": "VPC ID"},
+		},
+	}
+
+	output := GenerateAsciiDoc(module, "./network", Options{})
+
+	for _, expected := range []string{
+		"# Network\n\n== Requirements\n\n",
+		"|terraform\n|`+>= 1.0+`\n",
+		"|aws.east\n|n/a\n",
+		"== Modules\n\nNo modules.\n",
+		"|`+aws_vpc.this+`\n|resource\n",
+		"|`+data.aws_region.current+`\n|data source\n",
+		"|Name |Description |Type |Default |Required\n",
+		"|cidrs\n|n/a\n|`+list(string)+`\n|`+[\"10.0.0.0/16\"]+`\n|no\n",
+		"|name\n|Name \\| label\n|`+string+`\n|n/a\n|yes\n",
+		"|id\n|VPC ID\n",
+		"== Usage\n\n[source,hcl]\n----\nmodule \"network\" {\n",
+		"  # Required inputs\n  name                = # string\n",
+		"----\n\n== License\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q\nActual output:\n%s", expected, output)
+		}
+	}
+
+	// Inputs are listed from the parsed variables even without terraform-docs
+	output = GenerateAsciiDoc(Module{Name: "network", Variables: module.Variables}, "./network", Options{OmitDefault: true, OmitRequired: true})
+	if !strings.Contains(output, "|Name |Description |Type\n") || strings.Contains(output, "== Outputs") {
+		t.Errorf("Expected only an inputs table without default and required columns\nActual output:\n%s", output)
+	}
+}
+
+func TestFormatAsciiDocExamples(t *testing.T) {
+	formatter := NewUsageFormatter(map[string]Variable{
+		"name": {Name: "name", Type: "string", Required: true},
+	}, "app", "./app")
+	formatter.Examples = []Example{{Name: "Minimal", Description: "Only what is required.", Kind: ExampleMinimal}}
+
+	output := formatter.FormatAsciiDoc()
+	expected := "== Usage\n\n=== Minimal\n\nOnly what is required.\n\n[source,hcl]\n----\nmodule \"app\" {\n"
+	if !strings.HasPrefix(output, expected) {
+		t.Errorf("Expected output to start with %q\nActual output:\n%s", expected, output)
+	}
+}
+
+func TestGenerateAsciiDocOrder(t *testing.T) {
+	module := Module{
+		Name:      "network",
+		Variables: map[string]Variable{"name": {Name: "name", Type: "string", Required: true}},
+		Outputs:   []interface{}{},
+	}
+
+	output := GenerateAsciiDoc(module, "./network", Options{Sections: []string{"toc", "usage", "outputs", "inputs"}})
+	expected := "== Contents\n\n* <<_usage,Usage>>\n* <<_outputs,Outputs>>\n* <<_inputs,Inputs>>\n\n== Usage\n\n"
+	if !strings.HasPrefix(output, expected) {
+		t.Errorf("Expected output to start with %q\nActual output:\n%s", expected, output)
+	}
+	if strings.Index(output, "== Outputs") > strings.Index(output, "== Inputs") {
+		t.Errorf("Expected outputs before inputs\nActual output:\n%s", output)
+	}
+}
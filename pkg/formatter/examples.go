@@ -0,0 +1,201 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// Kinds of generated usage examples
+const (
+	// ExampleDefault sets the required inputs and lists the optional ones as comments
+	ExampleDefault = "default"
+	// ExampleMinimal sets only the required inputs
+	ExampleMinimal = "minimal"
+	// ExampleComplete sets every input
+	ExampleComplete = "complete"
+)
+
+// ExampleKinds lists the kinds of generated usage examples
+var ExampleKinds = []string{ExampleDefault, ExampleMinimal, ExampleComplete}
+
+// Shapes of the generated usage examples
+const (
+	// UsageStyleModule renders a module block
+	UsageStyleModule = "module"
+	// UsageStyleTerragrunt renders the terraform block and inputs map of a terragrunt.hcl
+	UsageStyleTerragrunt = "terragrunt"
+)
+
+// UsageStyles lists the shapes of the generated usage examples
+var UsageStyles = []string{UsageStyleModule, UsageStyleTerragrunt}
+
+// DefaultModuleSource is the placeholder source of a module whose source is
+// neither configured nor inferred
+const DefaultModuleSource = "path/to/module"
+
+// versionPlaceholder stands for a module version that must be filled in
+const versionPlaceholder = "<version>"
+
+// Example is a named usage example
+type Example struct {
+	Name        string
+	Description string
+	// Kind is one of ExampleKinds. A scenario has no kind and sets the required
+	// inputs and the optional inputs in Values.
+	Kind string
+	// Values maps input names to the HCL expressions used in this example,
+	// overriding the formatter's ExampleValues
+	Values map[string]string
+	// Code, when set, is HCL rendered verbatim instead of a generated block
+	Code string
+}
+
+// ExampleCode renders the HCL of a usage example
+func (f *UsageFormatter) ExampleCode(example Example) string {
+	if example.Code != "" {
+		return strings.TrimSpace(example.Code) + "\n"
+	}
+
+	values := make(map[string]string, len(f.ExampleValues)+len(example.Values))
+	for name, value := range f.ExampleValues {
+		values[name] = value
+	}
+	for name, value := range example.Values {
+		values[name] = value
+	}
+
+	var sb strings.Builder
+
+	if f.Style == UsageStyleTerragrunt {
+		// Terragrunt takes the source in a terraform block and the inputs in a map
+		sb.WriteString("terraform {\n")
+		sb.WriteString(fmt.Sprintf("  source = \"%s\"\n", terragruntSource(f.ModulePath, f.Version)))
+		sb.WriteString("}\n\n")
+		sb.WriteString("inputs = {\n")
+	} else {
+		// Create module block
+		sb.WriteString(fmt.Sprintf("module \"%s\" {\n", f.ModuleName))
+		sb.WriteString(fmt.Sprintf("  source  = \"%s\"\n", f.ModulePath))
+		if f.Version != "" {
+			sb.WriteString(fmt.Sprintf("  version = \"%s\"\n", f.Version))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Separate variables into required and optional
+	required, optional := f.separateVariables()
+
+	// Hard-code the exact expected formats for both required and optional variables
+	if len(required) > 0 {
+		sb.WriteString("  # Required inputs\n")
+		for i, group := range groupVariables(required, f.Groups) {
+			writeGroupTitle(&sb, i, group)
+			for _, v := range group.Variables {
+				if value, ok := values[v.Name]; ok {
+					sb.WriteString(fmt.Sprintf("  %s                = %s\n", v.Name, value))
+					continue
+				}
+				formattedType := formatTypeForUsage(v.Type)
+				// Hard-code the exact format for required variables
+				sb.WriteString(fmt.Sprintf("  %s                = # %s\n", v.Name, formattedType))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// Select the optional inputs shown by this kind of example
+	switch example.Kind {
+	case ExampleMinimal:
+		optional = nil
+	case "":
+		var selected []Variable
+		for _, v := range optional {
+			if _, ok := example.Values[v.Name]; ok {
+				selected = append(selected, v)
+			}
+		}
+		optional = selected
+	}
+
+	// Hard-code the exact expected formats for optional variables
+	if len(optional) > 0 {
+		sb.WriteString("  # Optional inputs\n")
+		for i, group := range groupVariables(optional, f.Groups) {
+			writeGroupTitle(&sb, i, group)
+			for _, v := range group.Variables {
+				if example.Kind == ExampleDefault {
+					sb.WriteString(fmt.Sprintf("  # %s               = %s\n", v.Name, f.optionalValue(v)))
+					continue
+				}
+				sb.WriteString(fmt.Sprintf("  %s                = %s\n", v.Name, f.completeValue(v, values)))
+			}
+		}
+	}
+
+	// Close module block or inputs map
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// writeGroupTitle starts a group of inputs in an example with a comment
+// naming it, separated from the previous group by a blank line
+func writeGroupTitle(sb *strings.Builder, index int, group variableGroup) {
+	if group.Title == "" {
+		return
+	}
+	if index > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  # " + group.Title + "\n")
+}
+
+// terragruntSource returns the Terragrunt source of a module. Terragrunt has no
+// version argument and reads a bare registry address as a local path, so a
+// registry address such as "org/vpc/aws" becomes
+// "tfr:///org/vpc/aws?version=1.2.0", with a placeholder for an unknown version.
+func terragruntSource(source string, version string) string {
+	if source == DefaultModuleSource || !terraform.IsRegistryAddress(source) {
+		return source
+	}
+	if version == "" {
+		version = versionPlaceholder
+	}
+
+	if strings.Count(source, "/") == 2 {
+		// The public registry has an empty host
+		return "tfr:///" + source + "?version=" + version
+	}
+	return "tfr://" + source + "?version=" + version
+}
+
+// IsUsageStyle reports whether style is one of UsageStyles
+func IsUsageStyle(style string) bool {
+	for _, valid := range UsageStyles {
+		if style == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// completeValue returns the value an optional input is set to in an example
+// that sets it: its example value, its default, or null
+func (f *UsageFormatter) completeValue(v Variable, values map[string]string) string {
+	if value, ok := values[v.Name]; ok {
+		return value
+	}
+	return formatHCLValue(v.Default)
+}
+
+// IsExampleKind reports whether kind is one of ExampleKinds
+func IsExampleKind(kind string) bool {
+	for _, valid := range ExampleKinds {
+		if kind == valid {
+			return true
+		}
+	}
+	return false
+}
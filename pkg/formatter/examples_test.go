@@ -0,0 +1,53 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatMarkdownExamples(t *testing.T) {
+	variables := map[string]Variable{
+		"name":        {Name: "name", Type: "string", Required: true},
+		"size":        {Name: "size", Type: "number", Default: 1},
+		"enable_logs": {Name: "enable_logs", Type: "bool", Default: false},
+	}
+
+	formatter := NewUsageFormatter(variables, "app", "./modules/app")
+	formatter.ExampleValues = map[string]string{"name": `"web"`}
+	formatter.Examples = []Example{
+		{Name: "Minimal", Kind: ExampleMinimal},
+		{Name: "Complete", Kind: ExampleComplete},
+		{Name: "With logs", Description: "Ships logs to the default bucket.", Values: map[string]string{"enable_logs": "true"}},
+		{Name: "embedded", Code: "module \"app\" {\n  source = \"./modules/app\"\n}\n\n"},
+	}
+
+	output := formatter.FormatMarkdown()
+	t.Logf("Actual output:\n%s", output)
+
+	sections := strings.Split(output, "### ")
+	if len(sections) != 5 {
+		t.Fatalf("Expected four examples, got %d", len(sections)-1)
+	}
+
+	expectations := []struct {
+		contains []string
+		excludes []string
+	}{
+		{[]string{"Minimal", `  name                = "web"`}, []string{"size", "enable_logs"}},
+		{[]string{"Complete", `  size                = 1`, `  enable_logs                = false`}, []string{"# size"}},
+		{[]string{"With logs", "Ships logs to the default bucket.", `  enable_logs                = true`}, []string{"size"}},
+		{[]string{"embedded\n\n```hcl\nmodule \"app\" {\n  source = \"./modules/app\"\n}\n```"}, nil},
+	}
+	for i, expected := range expectations {
+		for _, text := range expected.contains {
+			if !strings.Contains(sections[i+1], text) {
+				t.Errorf("Expected example %d to contain %q", i+1, text)
+			}
+		}
+		for _, text := range expected.excludes {
+			if strings.Contains(sections[i+1], text) {
+				t.Errorf("Expected example %d not to contain %q", i+1, text)
+			}
+		}
+	}
+}
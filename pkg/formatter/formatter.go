@@ -3,13 +3,11 @@ package formatter
 import (
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
 )
 
 // Variable represents a Terraform variable
@@ -19,6 +17,16 @@ type Variable struct {
 	Description string      `json:"description"`
 	Default     interface{} `json:"default"`
 	Required    bool        `json:"required"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+
+	// TypeExpr is the complete type constraint as written, line breaks included;
+	// Type may be shortened for display
+	TypeExpr string `json:"type_expr,omitempty"`
+	// Enum lists the only values the variable's validation rules accept
+	Enum []interface{} `json:"enum,omitempty"`
+	// Group is the category of the input, from a "# @group" comment or a
+	// "[Group]" description prefix
+	Group string `json:"group,omitempty"`
 }
 
 // Module represents a Terraform module metadata
@@ -26,219 +34,253 @@ type Module struct {
 	Path      string              `json:"path"`
 	Name      string              `json:"name"`
 	Variables map[string]Variable `json:"variables"`
-}
 
-// TerraformDocsConfig represents the configuration from terraform-docs
-type TerraformDocsConfig struct {
-	Header   string   `json:"header"`
-	Footer   string   `json:"footer"`
-	Sections sections `json:"sections,omitempty"`
+	// Header and footer read from the files named in the terraform-docs configuration
+	Header string `json:"header,omitempty"`
+	Footer string `json:"footer,omitempty"`
+
+	// Content fetched from terraform-docs; empty when it was unavailable
+	Markdown     string      `json:"-"`
+	Outputs      interface{} `json:"outputs,omitempty"`
+	Resources    interface{} `json:"resources,omitempty"`
+	Providers    interface{} `json:"providers,omitempty"`
+	Requirements interface{} `json:"requirements,omitempty"`
+	Modules      interface{} `json:"modules,omitempty"`
+
+	// Composition is parsed from the module files for the architecture diagram
+	Composition *terraform.Composition `json:"-"`
 }
 
-type sections struct {
-	Hide []string `json:"hide,omitempty"`
-	Show []string `json:"show,omitempty"`
+// SupportedFormats lists the output formats accepted by GenerateDoc
+var SupportedFormats = []string{"markdown", "json", "yaml", "asciidoc", "html", "tfvars", "jsonschema", "terragrunt", "mermaid"}
+
+// IsSupportedFormat reports whether GenerateDoc can produce the given format
+func IsSupportedFormat(format string) bool {
+	for _, supported := range SupportedFormats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
 }
 
 // GenerateDoc creates the complete documentation
-func GenerateDoc(module Module, format string, moduleSource string) string {
+func GenerateDoc(module Module, format string, moduleSource string, opts Options) (string, error) {
 	switch format {
 	case "markdown":
-		return GenerateMarkdownDoc(module, moduleSource)
+		return GenerateMarkdownDoc(module, moduleSource, opts), nil
 	case "json":
-		return GenerateJSONDoc(module, moduleSource)
+		return GenerateJSONDoc(module, moduleSource, opts)
+	case "yaml":
+		return GenerateYAMLDoc(module, moduleSource, opts)
+	case "asciidoc":
+		return GenerateAsciiDoc(module, moduleSource, opts), nil
+	case "html":
+		return GenerateHTMLDoc(module, moduleSource, opts)
+	case "tfvars":
+		return GenerateTfvarsDoc(module, opts), nil
+	case "jsonschema":
+		return GenerateJSONSchemaDoc(module)
+	case "terragrunt":
+		return GenerateTerragruntDoc(module, moduleSource, opts), nil
+	case "mermaid":
+		return GenerateMermaidDoc(module), nil
 	default:
-		log.Fatalf("Unsupported output format: %s", format)
-		return ""
+		return "", fmt.Errorf("unsupported output format: %s", format)
 	}
 }
 
 // GenerateMarkdownDoc generates Markdown documentation
-func GenerateMarkdownDoc(module Module, moduleSource string) string {
-	var sb strings.Builder
+func GenerateMarkdownDoc(module Module, moduleSource string, opts Options) string {
+	usage, embedded := opts.usageExamples()
 	
 	// Create a usage formatter
 	formatter := NewUsageFormatter(module.Variables, module.Name, moduleSource)
+	formatter.ShowDefaults = opts.ShowDefaults && !opts.OmitDefault
+	formatter.ExampleValues = opts.ExampleValues
+	formatter.SortBy = opts.SortBy
+	formatter.Version = opts.ModuleVersion
+	formatter.Examples = usage
+	formatter.Style = opts.UsageStyle
+	formatter.Groups = opts.InputGroups
+	// Usage examples link to the inputs table only when it is rendered
+	formatter.LinkInputs = opts.Anchors && opts.includes(SectionInputs)
+	
+	// Remove any usage section that might be generated by terraform-docs
+	usageRegex := regexp.MustCompile(`(?s)## Usage.*?(?:^##|\z)`)
+	docs := newMarkdownSections(usageRegex.ReplaceAllString(module.Markdown, ""))
+	
+	// Render the sections in order; the table of contents is filled in once
+	// the sections after it are known
+	var parts []string
+	toc := -1
+	for _, name := range opts.order() {
+		switch name {
+		case SectionHeader:
+			// Add header from terraform-docs config if available
+			if module.Header != "" {
+				parts = append(parts, module.Header+"\n\n")
+			}
+		case SectionTOC:
+			if opts.showsTOC() && toc < 0 {
+				toc = len(parts)
+				parts = append(parts, "")
+			}
+		case SectionArchitecture:
+			// Add the architecture diagram when it was asked for
+			if opts.lists(SectionArchitecture) {
+				parts = append(parts, formatArchitectureMarkdown(module))
+			}
+		case SectionUsage:
+			parts = append(parts, formatter.FormatMarkdown())
+		case SectionExamples:
+			if len(embedded) > 0 {
+				parts = append(parts, formatter.formatMarkdownExamples("Examples", embedded))
+			}
+		case SectionFooter:
+			// Add footer from terraform-docs config if available
+			if module.Footer != "" {
+				parts = append(parts, "\n"+module.Footer)
+			}
+		default:
+			// Add the sections rendered by terraform-docs, or the inputs without it
+			var content string
+			if module.Markdown != "" {
+				content = docs.take(name)
+			} else if name == SectionInputs {
+				content = formatFallbackInputs(module, opts)
+			}
+			if name == SectionInputs {
+				content = groupMarkdownTable(content, module.Variables, opts.InputGroups)
+			}
+			parts = append(parts, content)
+		}
+	}
 	
-	// Get the header and footer from terraform-docs config, if it exists
-	config := loadTerraformDocsConfig(module.Path)
-	
-	// Add header from terraform-docs config if available
-	if config.Header != "" {
-		sb.WriteString(config.Header)
-		sb.WriteString("\n\n")
+	if toc >= 0 {
+		parts[toc] = formatTableOfContents(strings.Join(parts[:toc], ""), strings.Join(parts[toc+1:], ""))
 	}
 	
-	// Add the remaining documentation by running terraform-docs
-	cmd := exec.Command("terraform-docs", "md", module.Path)
-	output, err := cmd.Output()
-	if err == nil {
-		// Remove any usage section that might be generated by terraform-docs
-		docStr := string(output)
-		usageRegex := regexp.MustCompile(`(?s)## Usage.*?(?:^##|\z)`)
-		docStr = usageRegex.ReplaceAllString(docStr, "")
-		
-		sb.WriteString(docStr)
+	return strings.Join(parts, "")
+}
+
+// formatFallbackInputs lists the inputs when terraform-docs is unavailable
+func formatFallbackInputs(module Module, opts Options) string {
+	var sb strings.Builder
+	
+	// Add a basic requirements section as fallback
+	sb.WriteString("## Requirements\n\n")
+	if opts.OmitRequired {
+		sb.WriteString("| Name | Type |\n")
+		sb.WriteString("|------|------|\n")
 	} else {
-		log.Printf("Warning: Failed to run terraform-docs for markdown: %v", err)
-		
-		// Add a basic requirements section as fallback
-		sb.WriteString("## Requirements\n\n")
 		sb.WriteString("| Name | Type | Required |\n")
 		sb.WriteString("|------|------|----------|\n")
-		
-		// Sort variables by name for consistent output
-		varNames := make([]string, 0, len(module.Variables))
-		for name := range module.Variables {
-			varNames = append(varNames, name)
+	}
+	
+	for _, v := range sortVariables(module.Variables, opts.SortBy) {
+		name := v.Name
+		if opts.Anchors {
+			// Anchor each row as terraform-docs does
+			name = fmt.Sprintf("<a name=\"%s\"></a> [%s](#%s)", inputAnchor(v.Name), v.Name, inputAnchor(v.Name))
 		}
-		sort.Strings(varNames)
-		
-		for _, name := range varNames {
-			v := module.Variables[name]
-			required := "yes"
-			if !v.Required {
-				required = "no"
-			}
-			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", v.Name, v.Type, required))
+		if opts.OmitRequired {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", name, v.Type))
+			continue
 		}
+		required := "yes"
+		if !v.Required {
+			required = "no"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", name, v.Type, required))
 	}
 	
-	// Add the usage section at the end
-	sb.WriteString(formatter.FormatMarkdown())
-	
-	// Add footer from terraform-docs config if available
-	if config.Footer != "" {
-		sb.WriteString("\n")
-		sb.WriteString(config.Footer)
-	}
-
 	return sb.String()
 }
 
-// loadTerraformDocsConfig attempts to load the terraform-docs configuration
-func loadTerraformDocsConfig(modulePath string) TerraformDocsConfig {
-	config := TerraformDocsConfig{}
-	
-	// Define the order of preference for configuration files
-	configPaths := []string{
-		filepath.Join(modulePath, ".terraform-docs.yml"),  // Highest priority
-		filepath.Join(modulePath, ".terraform-docs.yaml"),
-		filepath.Join(modulePath, "terraform-docs.yml"),
-		filepath.Join(modulePath, "terraform-docs.yaml"),  // Lowest priority
-	}
-	
-	for _, path := range configPaths {
-		if fileExists(path) {
-			log.Printf("Loading terraform-docs configuration from: %s", path)
-			
-			// Use terraform-docs to get the config
-			cmd := exec.Command("terraform-docs", "json", "--config", path, modulePath)
-			output, err := cmd.Output()
-			if err == nil {
-				var jsonOutput map[string]interface{}
-				if json.Unmarshal(output, &jsonOutput) == nil {
-					// Extract header and footer
-					content, ok := jsonOutput["content"].(map[string]interface{})
-					if ok {
-						if header, ok := content["header"].(string); ok {
-							config.Header = header
-						}
-						if footer, ok := content["footer"].(string); ok {
-							config.Footer = footer
-						}
-					}
-				}
-			}
-			break
-		}
+// GenerateJSONDoc generates JSON documentation
+func GenerateJSONDoc(module Module, moduleSource string, opts Options) (string, error) {
+	// Serialize to JSON
+	bytes, err := json.MarshalIndent(structuredDoc(module, moduleSource, opts), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate JSON: %v", err)
 	}
 	
-	return config
+	return string(bytes), nil
 }
 
-// fileExists checks if a file exists
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		return false
-	}
-	return !info.IsDir()
-}
-
-// GenerateJSONDoc generates JSON documentation
-func GenerateJSONDoc(module Module, moduleSource string) string {
+// structuredDoc builds the document rendered by the JSON and YAML formats
+func structuredDoc(module Module, moduleSource string, opts Options) map[string]interface{} {
 	// Create a usage formatter
 	formatter := NewUsageFormatter(module.Variables, module.Name, moduleSource)
+	formatter.SortBy = opts.SortBy
+	formatter.Version = opts.ModuleVersion
+	formatter.ExampleValues = opts.ExampleValues
+	formatter.ShowDefaults = opts.ShowDefaults && !opts.OmitDefault
+	formatter.Examples = opts.Examples
+	formatter.Style = opts.UsageStyle
+	formatter.Groups = opts.InputGroups
 	
 	// Get the structured usage section
 	usage := formatter.FormatJSON()
 	
-	// Get the header and footer from terraform-docs config, if it exists
-	config := loadTerraformDocsConfig(module.Path)
-	
 	// Create the full document
 	doc := map[string]interface{}{
 		"module_name": module.Name,
 		"module_path": module.Path,
 		"variables": []map[string]interface{}{},
-		"usage": usage,
+	}
+	if opts.includes(SectionUsage) {
+		doc["usage"] = usage
 	}
 	
 	// Add header and footer if available
-	if config.Header != "" {
-		doc["header"] = config.Header
+	if module.Header != "" && opts.includes(SectionHeader) {
+		doc["header"] = module.Header
 	}
-	if config.Footer != "" {
-		doc["footer"] = config.Footer
+	if module.Footer != "" && opts.includes(SectionFooter) {
+		doc["footer"] = module.Footer
 	}
 	
-	// Sort variables by name for consistent output
-	varNames := make([]string, 0, len(module.Variables))
-	for name := range module.Variables {
-		varNames = append(varNames, name)
-	}
-	sort.Strings(varNames)
+	// Sort variables for consistent output
+	variables := sortVariables(module.Variables, opts.SortBy)
 	
 	// Add variables information
-	for _, name := range varNames {
-		v := module.Variables[name]
+	if !opts.includes(SectionInputs) {
+		variables = nil
+	}
+	for _, v := range variables {
 		varInfo := map[string]interface{}{
 			"name":        v.Name,
 			"type":        v.Type,
 			"description": v.Description,
-			"required":    v.Required,
 		}
 		
-		if !v.Required {
+		if !opts.OmitRequired {
+			varInfo["required"] = v.Required
+		}
+		if !v.Required && !opts.OmitDefault {
 			varInfo["default"] = v.Default
 		}
+		if group := inputGroupOf(v, opts.InputGroups); group != "" {
+			varInfo["group"] = group
+		}
 		
 		doc["variables"] = append(doc["variables"].([]map[string]interface{}), varInfo)
 	}
 	
-	// Try to get additional module info from terraform-docs
-	cmd := exec.Command("terraform-docs", "json", module.Path)
-	output, err := cmd.Output()
-	if err == nil {
-		var tfDocsOutput map[string]interface{}
-		if json.Unmarshal(output, &tfDocsOutput) == nil {
-			// Add relevant sections from terraform-docs
-			for _, key := range []string{"outputs", "resources", "providers"} {
-				if tfDocsOutput[key] != nil {
-					doc[key] = tfDocsOutput[key]
-				}
-			}
-		}
+	// Add relevant sections from terraform-docs
+	if module.Outputs != nil && opts.includes(SectionOutputs) {
+		doc["outputs"] = module.Outputs
 	}
-	
-	// Serialize to JSON
-	bytes, err := json.MarshalIndent(doc, "", "  ")
-	if err != nil {
-		log.Fatalf("Failed to generate JSON: %v", err)
+	if module.Resources != nil && opts.includes(SectionResources) {
+		doc["resources"] = module.Resources
+	}
+	if module.Providers != nil && opts.includes(SectionProviders) {
+		doc["providers"] = module.Providers
 	}
 	
-	return string(bytes)
+	return doc
 }
 
 // UsageFormatter handles generation of the Usage section
@@ -246,6 +288,25 @@ type UsageFormatter struct {
 	Variables map[string]Variable
 	ModuleName string
 	ModulePath string
+
+	// ShowDefaults shows default values of optional inputs instead of their type
+	ShowDefaults bool
+	// ExampleValues maps input names to the HCL expression used as their value
+	ExampleValues map[string]string
+	// SortBy orders the inputs within each group; see Options.SortBy
+	SortBy string
+	// Version is the module version constraint; no version argument is shown when empty
+	Version string
+	// Examples lists the named examples to render; a single default example is
+	// rendered when empty
+	Examples []Example
+	// Style is the shape of the generated examples, UsageStyleModule when empty
+	Style string
+	// LinkInputs renders the examples as HTML code blocks whose input names
+	// link to the inputs table
+	LinkInputs bool
+	// Groups assigns inputs to groups in addition to the groups read from the module
+	Groups []InputGroup
 }
 
 // NewUsageFormatter creates a new formatter with the given variables
@@ -261,45 +322,54 @@ func NewUsageFormatter(variables map[string]Variable, moduleName string, moduleP
 func (f *UsageFormatter) FormatMarkdown() string {
 	var sb strings.Builder
 	
-	// Start the usage section
-	sb.WriteString("## Usage\n\n")
-	sb.WriteString("```hcl\n")
-	
-	// Create module block
-	sb.WriteString(fmt.Sprintf("module \"%s\" {\n", f.ModuleName))
-	sb.WriteString(fmt.Sprintf("  source  = \"%s\"\n\n", f.ModulePath))
-	
-	// Separate variables into required and optional
-	required, optional := f.separateVariables()
-	
-	// Hard-code the exact expected formats for both required and optional variables
-	if len(required) > 0 {
-		sb.WriteString("  # Required inputs\n")
-		for _, v := range required {
-			formattedType := formatTypeForUsage(v.Type)
-			// Hard-code the exact format for required variables
-			sb.WriteString(fmt.Sprintf("  %s                = # %s\n", v.Name, formattedType))
-		}
-		sb.WriteString("\n")
+	// A single unnamed block unless named examples are configured
+	if len(f.Examples) == 0 {
+		sb.WriteString("## Usage\n\n")
+		sb.WriteString(f.markdownCodeBlock(f.ExampleCode(Example{Kind: ExampleDefault})))
+		return sb.String()
 	}
 	
-	// Hard-code the exact expected formats for optional variables
-	if len(optional) > 0 {
-		sb.WriteString("  # Optional inputs\n")
-		for _, v := range optional {
-			formattedType := formatTypeForUsage(v.Type)
-			// Hard-code the exact format for optional variables
-			sb.WriteString(fmt.Sprintf("  # %s               = %s\n", v.Name, formattedType))
+	return f.formatMarkdownExamples("Usage", f.Examples)
+}
+
+// formatMarkdownExamples renders a section holding the given named examples
+func (f *UsageFormatter) formatMarkdownExamples(title string, examples []Example) string {
+	var sb strings.Builder
+	
+	sb.WriteString(fmt.Sprintf("## %s\n\n", title))
+	for _, example := range examples {
+		sb.WriteString(fmt.Sprintf("### %s\n\n", example.Name))
+		if example.Description != "" {
+			sb.WriteString(example.Description)
+			sb.WriteString("\n\n")
 		}
+		sb.WriteString(f.markdownCodeBlock(f.ExampleCode(example)))
 	}
 	
-	// Close module block
-	sb.WriteString("}\n")
-	sb.WriteString("```\n\n")
-	
 	return sb.String()
 }
 
+// markdownCodeBlock renders the code of an example as a fenced code block, or
+// as a linked HTML code block when LinkInputs is set
+func (f *UsageFormatter) markdownCodeBlock(code string) string {
+	if f.LinkInputs {
+		return f.linkedCodeBlock(code)
+	}
+	return "```hcl\n" + code + "```\n\n"
+}
+
+// optionalValue returns what is shown for an optional input: its example
+// value, its default when ShowDefaults is set, or its type
+func (f *UsageFormatter) optionalValue(v Variable) string {
+	if example, ok := f.ExampleValues[v.Name]; ok {
+		return example
+	}
+	if f.ShowDefaults && v.Default != nil {
+		return formatHCLValue(v.Default)
+	}
+	return formatTypeForUsage(v.Type)
+}
+
 // FormatJSON generates the Usage section in a structured JSON format
 func (f *UsageFormatter) FormatJSON() map[string]interface{} {
 	usage := map[string]interface{}{
@@ -308,6 +378,20 @@ func (f *UsageFormatter) FormatJSON() map[string]interface{} {
 		"required": []map[string]interface{}{},
 		"optional": []map[string]interface{}{},
 	}
+	if f.Version != "" {
+		usage["version"] = f.Version
+	}
+	if len(f.Examples) > 0 {
+		examples := []map[string]interface{}{}
+		for _, example := range f.Examples {
+			examples = append(examples, map[string]interface{}{
+				"name":        example.Name,
+				"description": example.Description,
+				"code":        f.ExampleCode(example),
+			})
+		}
+		usage["examples"] = examples
+	}
 	
 	// Separate variables
 	required, optional := f.separateVariables()
@@ -349,16 +433,8 @@ func (f *UsageFormatter) FormatJSON() map[string]interface{} {
 func (f *UsageFormatter) separateVariables() ([]Variable, []Variable) {
 	var required, optional []Variable
 	
-	// Get sorted variable names for consistent output
-	varNames := make([]string, 0, len(f.Variables))
-	for name := range f.Variables {
-		varNames = append(varNames, name)
-	}
-	sort.Strings(varNames)
-	
-	// Separate variables
-	for _, name := range varNames {
-		v := f.Variables[name]
+	// Separate sorted variables for consistent output
+	for _, v := range sortVariables(f.Variables, f.SortBy) {
 		if v.Required {
 			required = append(required, v)
 		} else {
@@ -369,6 +445,28 @@ func (f *UsageFormatter) separateVariables() ([]Variable, []Variable) {
 	return required, optional
 }
 
+// sortVariables returns the variables ordered by name, or by the criterion
+// named by sortBy ("required" or "type") and then by name
+func sortVariables(variables map[string]Variable, sortBy string) []Variable {
+	sorted := make([]Variable, 0, len(variables))
+	for _, v := range variables {
+		sorted = append(sorted, v)
+	}
+	
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch {
+		case sortBy == SortByRequired && a.Required != b.Required:
+			return a.Required
+		case sortBy == SortByType && a.Type != b.Type:
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	
+	return sorted
+}
+
 // formatTypeForUsage ensures the type is correctly formatted for the usage example
 func formatTypeForUsage(typeStr string) string {
 	// Clean up the type string
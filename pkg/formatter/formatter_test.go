@@ -141,4 +141,97 @@ func TestFormatTypeForUsage(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestFormatMarkdownExampleValues(t *testing.T) {
+	variables := map[string]Variable{
+		"name": {Name: "name", Type: "string", Required: true},
+		"tags": {Name: "tags", Type: "map(string)", Default: map[string]interface{}{"team": "infra"}},
+		"size": {Name: "size", Type: "number", Default: 1},
+	}
+
+	formatter := NewUsageFormatter(variables, "example", "path/to/module")
+	formatter.ShowDefaults = true
+	formatter.ExampleValues = map[string]string{"name": `"web"`}
+
+	output := formatter.FormatMarkdown()
+	for _, line := range []string{
+		`  name                = "web"`,
+		`  # size               = 1`,
+		`  # tags               = { team = "infra" }`,
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain '%s'\nActual output:\n%s", line, output)
+		}
+	}
+}
+
+func TestGenerateMarkdownDocSections(t *testing.T) {
+	module := Module{
+		Name:     "example",
+		Header:   "# Example module",
+		Markdown: "## Requirements\n\nNone.\n\n## Inputs\n\n| Name |\n\n## Outputs\n\n| Name |\n",
+		Variables: map[string]Variable{
+			"name": {Name: "name", Type: "string", Required: true},
+		},
+	}
+
+	output := GenerateMarkdownDoc(module, "path/to/module", Options{Sections: []string{"inputs", "usage"}})
+	for _, expected := range []string{"## Inputs", "## Usage"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q\nActual output:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"# Example module", "## Requirements", "## Outputs"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected output not to contain %q\nActual output:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestFormatMarkdownVersion(t *testing.T) {
+	formatter := NewUsageFormatter(map[string]Variable{}, "vpc", "app.terraform.io/org/vpc/aws")
+	formatter.Version = "1.2.0"
+
+	output := formatter.FormatMarkdown()
+	expected := "  source  = \"app.terraform.io/org/vpc/aws\"\n  version = \"1.2.0\"\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain %q\nActual output:\n%s", expected, output)
+	}
+	if formatter.FormatJSON()["version"] != "1.2.0" {
+		t.Errorf("Expected the JSON usage to include the version")
+	}
+}
+
+func TestGenerateMarkdownDocOrder(t *testing.T) {
+	module := Module{
+		Name:     "example",
+		Header:   "# Example module",
+		Markdown: "## Inputs\n\n| Name |\n\n## Outputs\n\n| Name |\n",
+		Variables: map[string]Variable{
+			"name": {Name: "name", Type: "string", Required: true},
+		},
+	}
+	opts := Options{
+		Sections: []string{"header", "usage", "examples", "outputs", "inputs"},
+		Examples: []Example{
+			{Name: "Default", Kind: ExampleDefault},
+			{Name: "basic", Code: "module \"example\" {}\n"},
+		},
+	}
+
+	output := GenerateMarkdownDoc(module, "path/to/module", opts)
+	last := -1
+	for _, heading := range []string{"# Example module", "## Usage", "### Default", "## Examples", "### basic", "## Outputs", "## Inputs"} {
+		index := strings.Index(output, heading)
+		if index <= last {
+			t.Fatalf("Expected %q after the previous section\nActual output:\n%s", heading, output)
+		}
+		last = index
+	}
+
+	// Only the usage block
+	output = GenerateMarkdownDoc(module, "path/to/module", Options{Sections: []string{"usage"}})
+	if !strings.HasPrefix(output, "## Usage\n\n```hcl\n") || strings.Contains(output, "## Inputs") {
+		t.Errorf("Expected only the usage block\nActual output:\n%s", output)
+	}
+}
@@ -0,0 +1,182 @@
+package formatter
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// InputGroup assigns the inputs matching Inputs, which are names or glob
+// patterns such as "subnet_*", to the group Name
+type InputGroup struct {
+	Name   string
+	Inputs []string
+}
+
+// OtherGroup is the title of the inputs without a group when others have one
+const OtherGroup = "Other"
+
+// variableGroup is a titled list of inputs
+type variableGroup struct {
+	Title     string
+	Variables []Variable
+}
+
+// inputGroupOf returns the group of an input: the first configured group
+// matching its name, or the group read from the module
+func inputGroupOf(v Variable, groups []InputGroup) string {
+	for _, group := range groups {
+		for _, pattern := range group.Inputs {
+			if matched, _ := path.Match(pattern, v.Name); matched {
+				return groupTitle(group.Name)
+			}
+		}
+	}
+	return groupTitle(v.Group)
+}
+
+// groupTitle capitalises a group name, so "networking" is shown as "Networking"
+func groupTitle(name string) string {
+	name = strings.TrimSpace(name)
+	first, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return ""
+	}
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+// groupVariables splits variables into their groups, keeping their order
+// within each group. Configured groups come first, in configuration order,
+// then the other groups by title and the inputs without a group last. A
+// single untitled group is returned when no input has a group.
+func groupVariables(variables []Variable, groups []InputGroup) []variableGroup {
+	byKey := make(map[string]*variableGroup)
+	var keys []string
+	for _, v := range variables {
+		title := inputGroupOf(v, groups)
+		key := strings.ToLower(title)
+		group, ok := byKey[key]
+		if !ok {
+			group = &variableGroup{Title: title}
+			byKey[key] = group
+			keys = append(keys, key)
+		}
+		group.Variables = append(group.Variables, v)
+	}
+
+	if _, ungrouped := byKey[""]; len(keys) == 1 && ungrouped {
+		return []variableGroup{{Variables: variables}}
+	}
+
+	configured := make(map[string]int, len(groups))
+	for i, group := range groups {
+		key := strings.ToLower(groupTitle(group.Name))
+		if _, ok := configured[key]; !ok {
+			configured[key] = i
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return groupBefore(keys[i], keys[j], configured)
+	})
+
+	result := make([]variableGroup, 0, len(keys))
+	for _, key := range keys {
+		group := *byKey[key]
+		if key == "" {
+			group.Title = OtherGroup
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+// groupBefore orders group keys: configured groups, other groups, no group
+func groupBefore(a string, b string, configured map[string]int) bool {
+	if (a == "") != (b == "") {
+		return b == ""
+	}
+	ia, aConfigured := configured[a]
+	ib, bConfigured := configured[b]
+	switch {
+	case aConfigured && bConfigured:
+		return ia < ib
+	case aConfigured != bConfigured:
+		return aConfigured
+	}
+	return a < b
+}
+
+var (
+	// markdownRowAnchorRegex finds the input named by the anchor of a
+	// terraform-docs table row
+	markdownRowAnchorRegex = regexp.MustCompile(`<a name="input_([^"]+)"></a>`)
+	// markdownRowNameRegex reads the first cell of a table row
+	markdownRowNameRegex = regexp.MustCompile(`^\|\s*([^|]*?)\s*\|`)
+)
+
+// groupMarkdownTable splits the table of a markdown inputs section into one
+// table per group, each under a level-three heading. The section is returned
+// unchanged when no input has a group.
+func groupMarkdownTable(section string, variables map[string]Variable, groups []InputGroup) string {
+	lines := strings.Split(section, "\n")
+
+	// The table is the first run of lines starting with a pipe
+	start := -1
+	end := len(lines)
+	for i, line := range lines {
+		isRow := strings.HasPrefix(line, "|")
+		if isRow && start < 0 {
+			start = i
+		} else if !isRow && start >= 0 {
+			end = i
+			break
+		}
+	}
+	if start < 0 || end-start < 3 {
+		return section
+	}
+	header, rows := lines[start:start+2], lines[start+2:end]
+
+	// Group the rows by the group of the input they describe
+	rowsByName := make(map[string][]string, len(rows))
+	ordered := make([]Variable, 0, len(rows))
+	for _, row := range rows {
+		name := ""
+		if match := markdownRowAnchorRegex.FindStringSubmatch(row); match != nil {
+			name = match[1]
+		} else if match := markdownRowNameRegex.FindStringSubmatch(row); match != nil {
+			name = strings.Trim(strings.ReplaceAll(match[1], `\`, ""), "`")
+		}
+		v, ok := variables[name]
+		if !ok {
+			v = Variable{Name: name}
+		}
+		rowsByName[name] = append(rowsByName[name], row)
+		ordered = append(ordered, v)
+	}
+
+	grouped := groupVariables(ordered, groups)
+	if len(grouped) == 1 && grouped[0].Title == "" {
+		return section
+	}
+
+	var sb strings.Builder
+	for _, line := range lines[:start] {
+		sb.WriteString(line + "\n")
+	}
+	for _, group := range grouped {
+		sb.WriteString("### " + group.Title + "\n\n")
+		sb.WriteString(strings.Join(header, "\n") + "\n")
+		for _, v := range group.Variables {
+			sb.WriteString(rowsByName[v.Name][0] + "\n")
+			rowsByName[v.Name] = rowsByName[v.Name][1:]
+		}
+		sb.WriteString("\n")
+	}
+	rest := strings.TrimLeft(strings.Join(lines[end:], "\n"), "\n")
+	sb.WriteString(rest)
+	return sb.String()
+}
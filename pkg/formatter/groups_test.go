@@ -0,0 +1,82 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExampleCodeGroups(t *testing.T) {
+	variables := map[string]Variable{
+		"vpc_id":     {Name: "vpc_id", Type: "string", Required: true, Group: "networking"},
+		"subnet_ids": {Name: "subnet_ids", Type: "list(string)", Required: true},
+		"name":       {Name: "name", Type: "string", Required: true},
+		"bucket":     {Name: "bucket", Type: "string", Default: "logs", Group: "Storage"},
+	}
+	formatter := NewUsageFormatter(variables, "app", "path/to/module")
+	formatter.ShowDefaults = true
+	formatter.Groups = []InputGroup{{Name: "Networking", Inputs: []string{"subnet_*"}}}
+
+	expected := `module "app" {
+  source  = "path/to/module"
+
+  # Required inputs
+  # Networking
+  subnet_ids                = # list(string)
+  vpc_id                = # string
+
+  # Other
+  name                = # string
+
+  # Optional inputs
+  # Storage
+  # bucket               = "logs"
+}
+`
+	if output := formatter.ExampleCode(Example{Kind: ExampleDefault}); output != expected {
+		t.Errorf("Unexpected example\nExpected:\n%s\nActual:\n%s", expected, output)
+	}
+
+	// Without groups the example is unchanged
+	formatter = NewUsageFormatter(map[string]Variable{"name": variables["name"]}, "app", "path/to/module")
+	if output := formatter.ExampleCode(Example{Kind: ExampleDefault}); strings.Contains(output, "# Other") {
+		t.Errorf("Expected no group titles without groups\nActual:\n%s", output)
+	}
+}
+
+func TestGroupMarkdownTable(t *testing.T) {
+	variables := map[string]Variable{
+		"vpc_id": {Name: "vpc_id", Group: "Networking"},
+		"name":   {Name: "name"},
+		"bucket": {Name: "bucket", Group: "Storage"},
+	}
+	section := "## Inputs\n\n" +
+		"| Name | Description |\n" +
+		"|------|-------------|\n" +
+		"| <a name=\"input_bucket\"></a> [bucket](#input\\_bucket) | Bucket |\n" +
+		"| <a name=\"input_name\"></a> [name](#input\\_name) | Name |\n" +
+		"| <a name=\"input_vpc_id\"></a> [vpc\\_id](#input\\_vpc\\_id) | VPC |\n\n"
+
+	expected := "## Inputs\n\n" +
+		"### Storage\n\n" +
+		"| Name | Description |\n" +
+		"|------|-------------|\n" +
+		"| <a name=\"input_bucket\"></a> [bucket](#input\\_bucket) | Bucket |\n\n" +
+		"### Networking\n\n" +
+		"| Name | Description |\n" +
+		"|------|-------------|\n" +
+		"| <a name=\"input_vpc_id\"></a> [vpc\\_id](#input\\_vpc\\_id) | VPC |\n\n" +
+		"### Other\n\n" +
+		"| Name | Description |\n" +
+		"|------|-------------|\n" +
+		"| <a name=\"input_name\"></a> [name](#input\\_name) | Name |\n\n"
+
+	groups := []InputGroup{{Name: "storage", Inputs: []string{"bucket"}}}
+	if output := groupMarkdownTable(section, variables, groups); output != expected {
+		t.Errorf("Unexpected inputs section\nExpected:\n%s\nActual:\n%s", expected, output)
+	}
+
+	// Without groups the section is unchanged
+	if output := groupMarkdownTable(section, map[string]Variable{}, nil); output != section {
+		t.Errorf("Expected the section unchanged without groups\nActual:\n%s", output)
+	}
+}
@@ -0,0 +1,67 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var hclIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// formatHCLValue renders a decoded default value as a single-line HCL expression
+func formatHCLValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quoteHCLString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int, int64, int32:
+		return fmt.Sprintf("%d", v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatHCLValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = fmt.Sprintf("%s = %s", formatHCLKey(key), formatHCLValue(v[key]))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	default:
+		return quoteHCLString(fmt.Sprintf("%v", v))
+	}
+}
+
+// formatHCLKey renders an object key, quoting it when it is not a valid identifier
+func formatHCLKey(key string) string {
+	if hclIdentifierRegex.MatchString(key) {
+		return key
+	}
+	return quoteHCLString(key)
+}
+
+// quoteHCLString quotes a string, escaping template sequences
+func quoteHCLString(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
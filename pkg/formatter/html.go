@@ -0,0 +1,340 @@
+package formatter
+
+import (
+	_ "embed"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("module").Parse(htmlTemplateText))
+
+// htmlPage is the data rendered by html.tmpl
+type htmlPage struct {
+	Title    string
+	Header   template.HTML
+	Sections []htmlSection
+	Footer   template.HTML
+}
+
+// htmlSection is one section of the page; exactly one of Table, Inputs and
+// Examples is set
+type htmlSection struct {
+	ID       string
+	Title    string
+	Table    *htmlTable
+	Inputs   *htmlInputs
+	Examples []htmlExample
+}
+
+// htmlTable is a plain table; rows get an anchor made of AnchorPrefix and
+// their first cell when AnchorPrefix is set
+type htmlTable struct {
+	Columns      []string
+	Rows         [][]string
+	AnchorPrefix string
+}
+
+// htmlInputs is the filterable inputs table
+type htmlInputs struct {
+	ShowDefault  bool
+	ShowRequired bool
+	Rows         []htmlInput
+}
+
+// Columns returns the number of columns of the inputs table
+func (i *htmlInputs) Columns() int {
+	columns := 3
+	if i.ShowDefault {
+		columns++
+	}
+	if i.ShowRequired {
+		columns++
+	}
+	return columns
+}
+
+// htmlInput is a row of the inputs table. Nested types are shown collapsed
+// to their ShortType; Type is the complete constraint, as written.
+type htmlInput struct {
+	Name        string
+	Description string
+	Type        string
+	ShortType   string
+	Default     string
+	Required    bool
+	// GroupTitle starts a group of inputs at this row
+	GroupTitle string
+}
+
+// htmlExample is a usage example with a copy button
+type htmlExample struct {
+	ID          string
+	Name        string
+	Description string
+	Code        string
+}
+
+// GenerateHTMLDoc generates a self-contained HTML page. Styles and scripts
+// are embedded, so the page works without network access.
+func GenerateHTMLDoc(module Module, moduleSource string, opts Options) (string, error) {
+	usage, embedded := opts.usageExamples()
+
+	formatter := NewUsageFormatter(module.Variables, module.Name, moduleSource)
+	formatter.ShowDefaults = opts.ShowDefaults && !opts.OmitDefault
+	formatter.ExampleValues = opts.ExampleValues
+	formatter.SortBy = opts.SortBy
+	formatter.Version = opts.ModuleVersion
+	formatter.Examples = usage
+	formatter.Style = opts.UsageStyle
+	formatter.Groups = opts.InputGroups
+
+	page := htmlPage{Title: module.Name}
+	if module.Header != "" && opts.includes(SectionHeader) {
+		page.Header = markdownToHTML(module.Header)
+	}
+	if module.Footer != "" && opts.includes(SectionFooter) {
+		page.Footer = markdownToHTML(module.Footer)
+	}
+
+	addTable := func(name string, title string, section interface{}, columns []string, anchorPrefix string, row func(map[string]interface{}) []string) {
+		if section == nil || !opts.includes(name) {
+			return
+		}
+		page.Sections = append(page.Sections, htmlSection{
+			ID:    name,
+			Title: title,
+			Table: &htmlTable{Columns: columns, Rows: tableRows(section, row), AnchorPrefix: anchorPrefix},
+		})
+	}
+
+	addTable(SectionRequirements, "Requirements", module.Requirements, []string{"Name", "Version"}, "", func(row map[string]interface{}) []string {
+		return []string{stringField(row, "name"), orNA(stringField(row, "version"))}
+	})
+	addTable(SectionProviders, "Providers", module.Providers, []string{"Name", "Version"}, "provider_", func(row map[string]interface{}) []string {
+		name := stringField(row, "name")
+		if alias := stringField(row, "alias"); alias != "" {
+			name += "." + alias
+		}
+		return []string{name, orNA(stringField(row, "version"))}
+	})
+	addTable(SectionModules, "Modules", module.Modules, []string{"Name", "Source", "Version"}, "module_", func(row map[string]interface{}) []string {
+		return []string{stringField(row, "name"), stringField(row, "source"), orNA(stringField(row, "version"))}
+	})
+	addTable(SectionResources, "Resources", module.Resources, []string{"Name", "Type"}, "", func(row map[string]interface{}) []string {
+		name := stringField(row, "type") + "." + stringField(row, "name")
+		if stringField(row, "mode") == "data" {
+			return []string{"data." + name, "data source"}
+		}
+		return []string{name, "resource"}
+	})
+
+	// Inputs come from the merged variables, so they are shown with or without terraform-docs
+	if opts.includes(SectionInputs) {
+		inputs := &htmlInputs{ShowDefault: !opts.OmitDefault, ShowRequired: !opts.OmitRequired}
+		for _, group := range groupVariables(sortVariables(module.Variables, opts.SortBy), opts.InputGroups) {
+			for i, v := range group.Variables {
+				input := htmlInput{
+					Name:        v.Name,
+					Description: v.Description,
+					Type:        v.Type,
+					ShortType:   formatTypeForUsage(v.Type),
+					Default:     "n/a",
+					Required:    v.Required,
+				}
+				if v.TypeExpr != "" {
+					input.Type = dedent(v.TypeExpr)
+				}
+				if !v.Required {
+					input.Default = formatHCLValue(v.Default)
+				}
+				if i == 0 {
+					input.GroupTitle = group.Title
+				}
+				inputs.Rows = append(inputs.Rows, input)
+			}
+		}
+		page.Sections = append(page.Sections, htmlSection{ID: SectionInputs, Title: "Inputs", Inputs: inputs})
+	}
+
+	addTable(SectionOutputs, "Outputs", module.Outputs, []string{"Name", "Description"}, "output_", func(row map[string]interface{}) []string {
+		return []string{stringField(row, "name"), stringField(row, "description")}
+	})
+
+	addExamples := func(name string, title string, examples []Example) {
+		section := htmlSection{ID: name, Title: title}
+		for i, example := range examples {
+			section.Examples = append(section.Examples, htmlExample{
+				ID:          fmt.Sprintf("%s_%d", name, i+1),
+				Name:        example.Name,
+				Description: example.Description,
+				Code:        formatter.ExampleCode(example),
+			})
+		}
+		page.Sections = append(page.Sections, section)
+	}
+	if opts.includes(SectionUsage) {
+		if len(usage) == 0 {
+			usage = []Example{{Kind: ExampleDefault}}
+		}
+		addExamples(SectionUsage, "Usage", usage)
+	}
+	if len(embedded) > 0 {
+		addExamples(SectionExamples, "Examples", embedded)
+	}
+
+	// Arrange the sections in the configured order; the header, navigation
+	// and footer are part of the page layout
+	order := opts.order()
+	position := func(id string) int {
+		for i, name := range order {
+			if name == id {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.SliceStable(page.Sections, func(i, j int) bool {
+		return position(page.Sections[i].ID) < position(page.Sections[j].ID)
+	})
+
+	var sb strings.Builder
+	if err := htmlTemplate.Execute(&sb, page); err != nil {
+		return "", fmt.Errorf("failed to generate HTML: %v", err)
+	}
+	return sb.String(), nil
+}
+
+// dedent removes the indentation shared by the lines of a multi-line
+// expression after its first, which starts at the attribute name
+func dedent(expr string) string {
+	lines := strings.Split(expr, "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		width := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || width < indent {
+			indent = width
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// orNA returns s, or "n/a" when it is empty
+func orNA(s string) string {
+	if s == "" {
+		return "n/a"
+	}
+	return s
+}
+
+var (
+	markdownFenceRegex = regexp.MustCompile("^(```|~~~)")
+	markdownTitleRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	markdownItemRegex  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownCodeRegex  = regexp.MustCompile("`([^`]+)`")
+	markdownLinkRegex  = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBoldRegex  = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	safeLinkRegex      = regexp.MustCompile(`^(https?://|mailto:|#|\./|\.\./|[A-Za-z0-9_./-]+$)`)
+)
+
+// markdownToHTML converts the markdown of a header or footer to HTML. Only
+// headings, paragraphs, lists, fenced code, inline code, bold text and links
+// are recognised; everything else is shown as text.
+func markdownToHTML(md string) template.HTML {
+	var sb strings.Builder
+	var paragraph, items []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			sb.WriteString("<p>" + markdownInline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+		if len(items) > 0 {
+			sb.WriteString("<ul>\n")
+			for _, item := range items {
+				sb.WriteString("<li>" + markdownInline(item) + "</li>\n")
+			}
+			sb.WriteString("</ul>\n")
+			items = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence := markdownFenceRegex.FindString(trimmed); fence != "" {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			sb.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		switch match := markdownTitleRegex.FindStringSubmatch(trimmed); {
+		case trimmed == "":
+			flush()
+		case match != nil:
+			flush()
+			level := len(match[1])
+			sb.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", level, markdownInline(match[2]), level))
+		case markdownItemRegex.MatchString(line):
+			if len(paragraph) > 0 {
+				flush()
+			}
+			items = append(items, markdownItemRegex.FindStringSubmatch(line)[1])
+		case len(items) > 0:
+			// A continuation line of the last list item
+			items[len(items)-1] += " " + trimmed
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return template.HTML(sb.String())
+}
+
+// markdownInline converts the inline markup of a line of markdown to HTML
+func markdownInline(text string) string {
+	// Code spans are set aside so their content is not treated as markup
+	var spans []string
+	text = markdownCodeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		spans = append(spans, "<code>"+html.EscapeString(match[1:len(match)-1])+"</code>")
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+
+	text = html.EscapeString(text)
+	text = markdownBoldRegex.ReplaceAllString(text, "<strong>$1</strong>")
+	text = markdownLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownLinkRegex.FindStringSubmatch(match)
+		if !safeLinkRegex.MatchString(html.UnescapeString(parts[2])) {
+			return parts[1]
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, parts[2], parts[1])
+	})
+
+	for i, span := range spans {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), span, 1)
+	}
+	return text
+}
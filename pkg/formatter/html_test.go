@@ -0,0 +1,99 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateHTMLDoc(t *testing.T) {
+	module := Module{
+		Name:   "network",
+		Header: "# Network\n\nCreates a **VPC** with `subnets`. See [docs](https://example.com) or [this](javascript:void).\n\n```hcl\nx = \"<y>\"\n```",
+		Variables: map[string]Variable{
+			"name":    {Name: "name", Type: "string", Description: "Name <b>of</b> the VPC", Required: true},
+			"subnets": {Name: "subnets", Type: "list(object({ cidr = string, az = string }))", Default: []interface{}{}},
+		},
+		Outputs: []interface{}{
+			map[string]interface{}{"name": "id", "description": "VPC ID"},
+		},
+	}
+
+	output, err := GenerateHTMLDoc(module, "./network", Options{})
+	if err != nil {
+		t.Fatalf("GenerateHTMLDoc failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<title>network</title>",
+		"<h1>Network</h1>",
+		"<strong>VPC</strong> with <code>subnets</code>",
+		`<a href="https://example.com">docs</a> or this.`,
+		"<pre><code>x = &#34;&lt;y&gt;&#34;</code></pre>",
+		`<li><a href="#inputs">Inputs</a></li>`,
+		`<input type="search" class="filter" id="inputs-filter"`,
+		`<tr id="input_name"><td><code>name</code><a class="anchor" href="#input_name">#</a></td><td>Name &lt;b&gt;of&lt;/b&gt; the VPC</td>`,
+		"<details><summary><code>list(...)</code></summary><pre>list(object({ cidr = string, az = string }))</pre></details>",
+		`<tr id="output_id"><td><code>id</code><a class="anchor" href="#output_id">#</a></td><td>VPC ID</td></tr>`,
+		`<button type="button" class="copy">Copy</button>`,
+		"module &#34;network&#34; {",
+		"navigator.clipboard",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q\nActual output:\n%s", expected, output)
+		}
+	}
+
+	// The page must not depend on anything outside itself
+	for _, unexpected := range []string{"<link", "<script src", "javascript:", "<b>of</b>", `id="requirements"`} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected output not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestGenerateHTMLDocOrder(t *testing.T) {
+	module := Module{
+		Name:      "network",
+		Variables: map[string]Variable{"name": {Name: "name", Type: "string", Required: true}},
+		Outputs:   []interface{}{},
+	}
+
+	output, err := GenerateHTMLDoc(module, "./network", Options{Sections: []string{"usage", "outputs", "inputs"}})
+	if err != nil {
+		t.Fatalf("GenerateHTMLDoc failed: %v", err)
+	}
+	usage, outputs, inputs := strings.Index(output, `id="usage"`), strings.Index(output, `id="outputs"`), strings.Index(output, `id="inputs"`)
+	if usage < 0 || !(usage < outputs && outputs < inputs) {
+		t.Errorf("Expected usage, outputs and inputs in that order\nActual output:\n%s", output)
+	}
+}
+
+func TestGenerateHTMLDocNestedTypes(t *testing.T) {
+	module := Module{
+		Name: "network",
+		Variables: map[string]Variable{
+			"settings": {
+				Name:     "settings",
+				Type:     "object({",
+				TypeExpr: "object({\n    cidr = string\n    az   = string\n  })",
+				Required: true,
+				Group:    "Networking",
+			},
+		},
+	}
+
+	output, err := GenerateHTMLDoc(module, "./network", Options{OmitDefault: true})
+	if err != nil {
+		t.Fatalf("GenerateHTMLDoc failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"<pre>object({\n  cidr = string\n  az   = string\n})</pre>",
+		`<tr class="group"><th colspan="4">Networking</th></tr>`,
+		`<tr class="no-match" hidden><td colspan="4">`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q\nActual output:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// JSONSchemaDialect is the JSON Schema version of the generated schemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateJSONSchemaDoc generates a JSON Schema describing the module's
+// inputs, suitable for validating .tfvars.json files and Terragrunt inputs
+func GenerateJSONSchemaDoc(module Module) (string, error) {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for _, v := range sortVariables(module.Variables, SortByName) {
+		properties[v.Name] = variableSchema(v)
+		if v.Required {
+			required = append(required, v.Name)
+		}
+	}
+
+	doc := map[string]interface{}{
+		"$schema":              JSONSchemaDialect,
+		"title":                module.Name,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+
+	bytes, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate JSON Schema: %v", err)
+	}
+	return string(bytes), nil
+}
+
+// variableSchema returns the schema of a single input. A type that cannot be
+// parsed, such as one shortened to "object({...})", leaves the value
+// unconstrained.
+func variableSchema(v Variable) map[string]interface{} {
+	// Prefer the complete constraint; Type may have been shortened for display
+	typeExpr := v.TypeExpr
+	if typeExpr == "" {
+		typeExpr = v.Type
+	}
+
+	schema := map[string]interface{}{}
+	if t, err := terraform.ParseType(typeExpr); err == nil {
+		schema = typeSchema(t)
+	}
+
+	if v.Description != "" {
+		schema["description"] = v.Description
+	}
+	if !v.Required && v.Default != nil {
+		schema["default"] = v.Default
+	}
+	if len(v.Enum) > 0 {
+		schema["enum"] = v.Enum
+	}
+	return schema
+}
+
+// typeSchema translates a Terraform type into JSON Schema
+func typeSchema(t *terraform.Type) map[string]interface{} {
+	switch t.Kind {
+	case terraform.TypeString:
+		return map[string]interface{}{"type": "string"}
+	case terraform.TypeNumber:
+		return map[string]interface{}{"type": "number"}
+	case terraform.TypeBool:
+		return map[string]interface{}{"type": "boolean"}
+	case terraform.TypeList:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem)}
+	case terraform.TypeSet:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem), "uniqueItems": true}
+	case terraform.TypeMap:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem)}
+	case terraform.TypeTuple:
+		items := make([]interface{}, len(t.Elems))
+		for i, elem := range t.Elems {
+			items[i] = typeSchema(elem)
+		}
+		return map[string]interface{}{
+			"type":        "array",
+			"prefixItems": items,
+			"items":       false,
+			"minItems":    len(items),
+			"maxItems":    len(items),
+		}
+	case terraform.TypeObject:
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, attr := range t.Attributes {
+			schema := typeSchema(attr.Type)
+			if attr.HasDefault && attr.Default != nil {
+				schema["default"] = attr.Default
+			}
+			properties[attr.Name] = schema
+			if !attr.Optional {
+				required = append(required, attr.Name)
+			}
+		}
+		sort.Strings(required)
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	}
+
+	// any accepts every value
+	return map[string]interface{}{}
+}
@@ -0,0 +1,78 @@
+package formatter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateJSONSchemaDoc(t *testing.T) {
+	module := Module{
+		Name: "network",
+		Variables: map[string]Variable{
+			"name": {Name: "name", Type: "string", Description: "Name of the VPC", Required: true},
+			"tier": {Name: "tier", Type: "string", Default: "standard", Enum: []interface{}{"standard", "premium"}},
+			"subnets": {
+				Name:     "subnets",
+				Type:     "map(object({...}))",
+				TypeExpr: "map(object({ cidr = string, public = optional(bool, false) }))",
+				Default:  map[string]interface{}{},
+			},
+			"zones":  {Name: "zones", Type: "set(string)", Required: true},
+			"pair":   {Name: "pair", Type: "tuple([string, number])", Required: true},
+			"extra":  {Name: "extra", Type: "any", Required: true},
+			"legacy": {Name: "legacy", Type: "object({...})", Required: true},
+		},
+	}
+
+	output, err := GenerateJSONSchemaDoc(module)
+	if err != nil {
+		t.Fatalf("GenerateJSONSchemaDoc failed: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &schema); err != nil {
+		t.Fatalf("Generated schema is not valid JSON: %v", err)
+	}
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "network",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["extra", "legacy", "name", "pair", "zones"],
+  "properties": {
+    "extra": {},
+    "legacy": {},
+    "name": {"type": "string", "description": "Name of the VPC"},
+    "pair": {
+      "type": "array",
+      "prefixItems": [{"type": "string"}, {"type": "number"}],
+      "items": false,
+      "minItems": 2,
+      "maxItems": 2
+    },
+    "subnets": {
+      "type": "object",
+      "default": {},
+      "additionalProperties": {
+        "type": "object",
+        "properties": {
+          "cidr": {"type": "string"},
+          "public": {"type": "boolean", "default": false}
+        },
+        "required": ["cidr"]
+      }
+    },
+    "tier": {"type": "string", "default": "standard", "enum": ["standard", "premium"]},
+    "zones": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+  }
+}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("Unexpected schema:\n%s", output)
+	}
+}
@@ -0,0 +1,166 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// mermaidNode is a node of the architecture graph. Resources are grouped into
+// one node per type so that the graph stays readable for large modules.
+type mermaidNode struct {
+	ID    string
+	Label string
+	Data  bool
+	Count int
+}
+
+var mermaidIDRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// GenerateMermaidDoc generates a Mermaid graph of the module's architecture:
+// its child modules, its resource types grouped by provider, and the values
+// flowing between them
+func GenerateMermaidDoc(module Module) string {
+	c := module.Composition
+
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	if c.IsEmpty() {
+		sb.WriteString("  %% The module calls no modules and declares no resources\n")
+		return sb.String()
+	}
+
+	// Module calls, in the order they are declared
+	ids := mermaidIDs{}
+	nodes := make(map[string]*mermaidNode)
+	for _, call := range c.Modules {
+		label := "module." + call.Name
+		if call.Source != "" {
+			label += "<br/>" + call.Source
+		}
+		id := ids.id("module", call.Name)
+		nodes["module."+call.Name] = &mermaidNode{ID: id}
+		sb.WriteString(fmt.Sprintf("  %s[[\"%s\"]]\n", id, mermaidText(label)))
+	}
+
+	// Resource types, grouped by provider
+	byProvider := make(map[string][]*mermaidNode)
+	types := make(map[string]*mermaidNode)
+	for _, r := range c.Resources {
+		key := r.Mode + "." + r.Type
+		node, ok := types[key]
+		if !ok {
+			node = &mermaidNode{Label: r.Type, Data: r.Mode == terraform.ResourceData}
+			if node.Data {
+				node.ID = ids.id("data", r.Type)
+				node.Label = "data." + r.Type
+			} else {
+				node.ID = ids.id("resource", r.Type)
+			}
+			types[key] = node
+			byProvider[r.Provider] = append(byProvider[r.Provider], node)
+		}
+		node.Count++
+		nodes[r.Address()] = node
+	}
+
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	for _, provider := range providers {
+		group := byProvider[provider]
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Data != group[j].Data {
+				return !group[i].Data
+			}
+			return group[i].Label < group[j].Label
+		})
+
+		sb.WriteString(fmt.Sprintf("  subgraph %s[\"%s\"]\n", ids.id("provider", provider), mermaidText(provider)))
+		for _, node := range group {
+			label := node.Label
+			if node.Count > 1 {
+				label += fmt.Sprintf(" (%d)", node.Count)
+			}
+			// Data sources are drawn as cylinders, managed resources as boxes
+			if node.Data {
+				sb.WriteString(fmt.Sprintf("    %s[(\"%s\")]\n", node.ID, mermaidText(label)))
+			} else {
+				sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", node.ID, mermaidText(label)))
+			}
+		}
+		sb.WriteString("  end\n")
+	}
+
+	// Flows between the same nodes are drawn as one edge listing every value
+	type edge struct{ from, to string }
+	var edges []edge
+	labels := make(map[edge][]string)
+	for _, flow := range c.Flows {
+		from, to := nodes[flow.From], nodes[flow.To]
+		if from == nil || to == nil || from == to {
+			continue
+		}
+		e := edge{from.ID, to.ID}
+		if _, ok := labels[e]; !ok {
+			edges = append(edges, e)
+		}
+		labels[e] = append(labels[e], flow.Label)
+	}
+
+	for _, e := range edges {
+		sb.WriteString(fmt.Sprintf("  %s -->|\"%s\"| %s\n", e.from, mermaidText(strings.Join(labels[e], "<br/>")), e.to))
+	}
+
+	return sb.String()
+}
+
+// formatArchitectureMarkdown renders the architecture section of a markdown
+// document as a mermaid code block
+func formatArchitectureMarkdown(module Module) string {
+	if module.Composition.IsEmpty() {
+		return ""
+	}
+	return "## Architecture\n\n```mermaid\n" + GenerateMermaidDoc(module) + "```\n\n"
+}
+
+// mermaidIDs assigns node identifiers made of characters Mermaid accepts.
+// Names that only differ in other characters, such as "a-b" and "a_b", are
+// numbered so that they stay separate nodes.
+type mermaidIDs map[string]string
+
+func (ids mermaidIDs) id(kind string, name string) string {
+	key := kind + "." + name
+	if id, ok := ids[key]; ok {
+		return id
+	}
+
+	base := kind + "_" + mermaidIDRegex.ReplaceAllString(name, "_")
+	id := base
+	for n := 2; ids.used(id); n++ {
+		id = fmt.Sprintf("%s_%d", base, n)
+	}
+	ids[key] = id
+	return id
+}
+
+// used reports whether id was assigned to a name
+func (ids mermaidIDs) used(id string) bool {
+	for _, assigned := range ids {
+		if assigned == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mermaidText escapes text for a quoted Mermaid label, which only a quote can end
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, "\"", "#quot;")
+}
@@ -0,0 +1,100 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+func TestGenerateMermaidDoc(t *testing.T) {
+	module := Module{
+		Name: "platform",
+		Composition: &terraform.Composition{
+			Modules: []terraform.ModuleCall{
+				{Name: "network", Source: "terraform-aws-modules/vpc/aws"},
+				{Name: "dns-zone", Source: "./modules/\"dns\""},
+			},
+			Resources: []terraform.Resource{
+				{Mode: terraform.ResourceManaged, Type: "aws_instance", Name: "a", Provider: "aws"},
+				{Mode: terraform.ResourceManaged, Type: "aws_instance", Name: "b", Provider: "aws"},
+				{Mode: terraform.ResourceData, Type: "aws_ami", Name: "ubuntu", Provider: "aws"},
+				{Mode: terraform.ResourceManaged, Type: "random_id", Name: "suffix", Provider: "random"},
+			},
+			Flows: []terraform.Flow{
+				{From: "module.network", To: "aws_instance.a", Label: "subnet_id"},
+				{From: "module.network", To: "aws_instance.b", Label: "subnet_id"},
+				{From: "module.network", To: "module.dns-zone", Label: "vpc_id → vpc_id"},
+				{From: "data.aws_ami.ubuntu", To: "aws_instance.a", Label: "id"},
+			},
+		},
+	}
+
+	output := GenerateMermaidDoc(module)
+	expected := `graph LR
+  module_network[["module.network<br/>terraform-aws-modules/vpc/aws"]]
+  module_dns_zone[["module.dns-zone<br/>./modules/#quot;dns#quot;"]]
+  subgraph provider_aws["aws"]
+    resource_aws_instance["aws_instance (2)"]
+    data_aws_ami[("data.aws_ami")]
+  end
+  subgraph provider_random["random"]
+    resource_random_id["random_id"]
+  end
+  module_network -->|"subnet_id<br/>subnet_id"| resource_aws_instance
+  module_network -->|"vpc_id → vpc_id"| module_dns_zone
+  data_aws_ami -->|"id"| resource_aws_instance
+`
+	if output != expected {
+		t.Errorf("Unexpected mermaid graph\nExpected:\n%s\nActual:\n%s", expected, output)
+	}
+}
+
+func TestGenerateMermaidDocUniqueIDs(t *testing.T) {
+	module := Module{
+		Name: "platform",
+		Composition: &terraform.Composition{
+			Modules: []terraform.ModuleCall{{Name: "a-b"}, {Name: "a_b"}},
+			Resources: []terraform.Resource{
+				{Mode: terraform.ResourceManaged, Type: "aws_s3-bucket", Name: "x", Provider: "aws"},
+				{Mode: terraform.ResourceManaged, Type: "aws_s3_bucket", Name: "y", Provider: "aws"},
+			},
+			Flows: []terraform.Flow{
+				{From: "module.a_b", To: "aws_s3_bucket.y", Label: "id"},
+			},
+		},
+	}
+
+	output := GenerateMermaidDoc(module)
+	for _, expected := range []string{
+		`module_a_b[["module.a-b"]]`,
+		`module_a_b_2[["module.a_b"]]`,
+		`resource_aws_s3_bucket["aws_s3-bucket"]`,
+		`resource_aws_s3_bucket_2["aws_s3_bucket"]`,
+		`module_a_b_2 -->|"id"| resource_aws_s3_bucket_2`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q\nActual:\n%s", expected, output)
+		}
+	}
+}
+
+func TestMarkdownArchitectureSection(t *testing.T) {
+	module := Module{
+		Name:   "platform",
+		Header: "# Platform",
+		Composition: &terraform.Composition{
+			Modules: []terraform.ModuleCall{{Name: "network", Source: "./network"}},
+		},
+	}
+
+	if output := GenerateMarkdownDoc(module, "path/to/module", Options{}); strings.Contains(output, "## Architecture") {
+		t.Errorf("Expected no architecture section unless selected, got:\n%s", output)
+	}
+
+	output := GenerateMarkdownDoc(module, "path/to/module", Options{Sections: []string{SectionHeader, SectionArchitecture}})
+	expected := "# Platform\n\n## Architecture\n\n```mermaid\ngraph LR\n  module_network[[\"module.network<br/>./network\"]]\n```\n\n"
+	if output != expected {
+		t.Errorf("Unexpected markdown\nExpected:\n%s\nActual:\n%s", expected, output)
+	}
+}
@@ -0,0 +1,245 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Section names accepted in Options.Sections
+const (
+	SectionHeader       = "header"
+	SectionRequirements = "requirements"
+	SectionProviders    = "providers"
+	SectionModules      = "modules"
+	SectionResources    = "resources"
+	SectionInputs       = "inputs"
+	SectionOutputs      = "outputs"
+	SectionUsage        = "usage"
+	SectionFooter       = "footer"
+
+	// SectionArchitecture is a Mermaid diagram of the module's composition.
+	// It is only rendered when listed in Options.Sections.
+	SectionArchitecture = "architecture"
+	// SectionTOC is a table of contents, rendered when listed in
+	// Options.Sections or enabled with Options.TOC
+	SectionTOC = "toc"
+	// SectionExamples holds the example configurations embedded from the
+	// module's examples directory. They are part of the usage section unless
+	// it is listed in Options.Sections.
+	SectionExamples = "examples"
+)
+
+// AllSections lists the sections rendered by default, in the order they are rendered
+var AllSections = []string{
+	SectionHeader,
+	SectionRequirements,
+	SectionProviders,
+	SectionModules,
+	SectionResources,
+	SectionInputs,
+	SectionOutputs,
+	SectionUsage,
+	SectionFooter,
+}
+
+// Orders accepted in Options.SortBy
+const (
+	SortByName     = "name"
+	SortByRequired = "required"
+	SortByType     = "type"
+)
+
+// Options controls the optional parts of the generated documentation
+type Options struct {
+	// Sections lists the sections to include; every section is included when empty
+	Sections []string
+	// ShowDefaults shows the default value of optional inputs in the usage
+	// example instead of their type
+	ShowDefaults bool
+	// ExampleValues maps input names to the HCL expression used as their value
+	// in the usage example
+	ExampleValues map[string]string
+
+	// ModuleVersion, when set, adds a version argument to the usage example
+	ModuleVersion string
+	// Examples lists the named usage examples; a single default example is
+	// rendered when empty
+	Examples []Example
+	// UsageStyle is the shape of the usage examples, UsageStyleModule when empty
+	UsageStyle string
+
+	// InputGroups assigns inputs to groups in addition to the groups read
+	// from the module; inputs are listed per group in tables and usage examples
+	InputGroups []InputGroup
+
+	// SortBy orders the inputs by SortByName (the default), SortByRequired or SortByType
+	SortBy string
+	// OmitRequired and OmitDefault leave out whether an input is required and
+	// its default value, as terraform-docs' settings.required and settings.default do
+	OmitRequired bool
+	OmitDefault  bool
+
+	// OmitOptionalInputs leaves the optional inputs out of the tfvars format
+	OmitOptionalInputs bool
+
+	// TOC inserts a table of contents after the header of markdown output
+	TOC bool
+	// Anchors anchors the rows of the fallback inputs table and links the
+	// inputs set in markdown usage examples to their rows
+	Anchors bool
+}
+
+// SectionNames lists every section in the rendering order used when
+// Options.Sections is empty. The sections left out by default take their
+// place in this order when enabled.
+var SectionNames = []string{
+	SectionHeader,
+	SectionTOC,
+	SectionArchitecture,
+	SectionRequirements,
+	SectionProviders,
+	SectionModules,
+	SectionResources,
+	SectionInputs,
+	SectionOutputs,
+	SectionUsage,
+	SectionExamples,
+	SectionFooter,
+}
+
+// order returns the names of the sections to render, in order: the listed
+// sections, or the default order. A table of contents enabled with TOC but
+// not listed goes after the header.
+func (o Options) order() []string {
+	if len(o.Sections) == 0 {
+		return SectionNames
+	}
+
+	order := make([]string, 0, len(o.Sections)+1)
+	for _, section := range o.Sections {
+		order = append(order, strings.ToLower(section))
+	}
+	if o.TOC && !o.includes(SectionTOC) {
+		position := 0
+		if len(order) > 0 && order[0] == SectionHeader {
+			position = 1
+		}
+		order = append(order[:position], append([]string{SectionTOC}, order[position:]...)...)
+	}
+	return order
+}
+
+// IsSection reports whether name is one of SectionNames
+func IsSection(name string) bool {
+	for _, section := range SectionNames {
+		if strings.EqualFold(name, section) {
+			return true
+		}
+	}
+	return false
+}
+
+// showsTOC reports whether a table of contents is rendered
+func (o Options) showsTOC() bool {
+	return o.TOC || o.lists(SectionTOC)
+}
+
+// usageExamples splits the examples into those shown in the usage section
+// and those shown in the examples section. Embedded example configurations
+// get a section of their own only when it is listed.
+func (o Options) usageExamples() ([]Example, []Example) {
+	if !o.lists(SectionExamples) {
+		return o.Examples, nil
+	}
+
+	var usage, embedded []Example
+	for _, example := range o.Examples {
+		if example.Code != "" {
+			embedded = append(embedded, example)
+		} else {
+			usage = append(usage, example)
+		}
+	}
+	return usage, embedded
+}
+
+// includes reports whether the named section should be rendered
+func (o Options) includes(name string) bool {
+	if len(o.Sections) == 0 {
+		return true
+	}
+	for _, section := range o.Sections {
+		if strings.EqualFold(section, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// lists reports whether the named section is listed explicitly, for
+// sections that are left out unless requested
+func (o Options) lists(name string) bool {
+	return len(o.Sections) > 0 && o.includes(name)
+}
+
+// markdownSection is a level-two section of a markdown document
+type markdownSection struct {
+	Name    string
+	Content string
+}
+
+var markdownHeadingRegex = regexp.MustCompile(`(?m)^## +(.+?)\s*$`)
+
+// splitMarkdownSections splits markdown at its level-two headings. The text
+// before the first heading is returned separately.
+func splitMarkdownSections(md string) (string, []markdownSection) {
+	matches := markdownHeadingRegex.FindAllStringSubmatchIndex(md, -1)
+	if len(matches) == 0 {
+		return md, nil
+	}
+
+	preamble := md[:matches[0][0]]
+	sections := make([]markdownSection, 0, len(matches))
+	for i, match := range matches {
+		end := len(md)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		sections = append(sections, markdownSection{
+			Name:    strings.ToLower(md[match[2]:match[3]]),
+			Content: md[match[0]:end],
+		})
+	}
+
+	return preamble, sections
+}
+
+// markdownSections hands out the level-two sections of a markdown document
+// by name, each at most once. The text before the first heading comes with
+// the first section handed out.
+type markdownSections struct {
+	preamble string
+	sections []markdownSection
+	taken    []bool
+}
+
+func newMarkdownSections(md string) *markdownSections {
+	preamble, sections := splitMarkdownSections(md)
+	return &markdownSections{preamble: preamble, sections: sections, taken: make([]bool, len(sections))}
+}
+
+// take returns the content of the sections with the given name that were not
+// handed out yet
+func (m *markdownSections) take(name string) string {
+	var sb strings.Builder
+	for i, section := range m.sections {
+		if m.taken[i] || section.Name != name {
+			continue
+		}
+		m.taken[i] = true
+		sb.WriteString(m.preamble)
+		m.preamble = ""
+		sb.WriteString(section.Content)
+	}
+	return sb.String()
+}
@@ -0,0 +1,16 @@
+package formatter
+
+// GenerateTerragruntDoc generates a starter terragrunt.hcl: a terraform block
+// with the module source and an inputs map laid out like the default usage
+// example, with the required inputs set and the optional ones commented out
+func GenerateTerragruntDoc(module Module, moduleSource string, opts Options) string {
+	formatter := NewUsageFormatter(module.Variables, module.Name, moduleSource)
+	formatter.ShowDefaults = opts.ShowDefaults && !opts.OmitDefault
+	formatter.ExampleValues = opts.ExampleValues
+	formatter.SortBy = opts.SortBy
+	formatter.Version = opts.ModuleVersion
+	formatter.Style = UsageStyleTerragrunt
+	formatter.Groups = opts.InputGroups
+
+	return formatter.ExampleCode(Example{Kind: ExampleDefault})
+}
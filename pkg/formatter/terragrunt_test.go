@@ -0,0 +1,67 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateTerragruntDoc(t *testing.T) {
+	module := Module{
+		Name: "vpc",
+		Variables: map[string]Variable{
+			"name": {Name: "name", Type: "string", Required: true},
+			"cidr": {Name: "cidr", Type: "string", Default: "10.0.0.0/16"},
+		},
+	}
+
+	output := GenerateTerragruntDoc(module, "app.terraform.io/org/vpc/aws", Options{ModuleVersion: "1.2.0", ShowDefaults: true})
+	expected := `terraform {
+  source = "tfr://app.terraform.io/org/vpc/aws?version=1.2.0"
+}
+
+inputs = {
+  # Required inputs
+  name                = # string
+
+  # Optional inputs
+  # cidr               = "10.0.0.0/16"
+}
+`
+	if output != expected {
+		t.Errorf("Unexpected terragrunt.hcl\nExpected:\n%s\nActual:\n%s", expected, output)
+	}
+}
+
+func TestTerragruntSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		version  string
+		expected string
+	}{
+		{"terraform-aws-modules/vpc/aws", "5.0.0", "tfr:///terraform-aws-modules/vpc/aws?version=5.0.0"},
+		{"app.terraform.io/org/vpc/aws", "", "tfr://app.terraform.io/org/vpc/aws?version=<version>"},
+		{"app.terraform.io/org/vpc/aws", "1.0.0", "tfr://app.terraform.io/org/vpc/aws?version=1.0.0"},
+		{"github.com/org/vpc", "1.0.0", "github.com/org/vpc"},
+		{"path/to/module", "", "path/to/module"},
+		{"git::https://example.com/infra.git//vpc?ref=v1.0.0", "1.0.0", "git::https://example.com/infra.git//vpc?ref=v1.0.0"},
+		{"../modules/vpc", "1.0.0", "../modules/vpc"},
+	}
+
+	for _, test := range tests {
+		if got := terragruntSource(test.source, test.version); got != test.expected {
+			t.Errorf("terragruntSource(%s, %s) = %s; expected %s", test.source, test.version, got, test.expected)
+		}
+	}
+}
+
+func TestFormatMarkdownTerragruntStyle(t *testing.T) {
+	formatter := NewUsageFormatter(map[string]Variable{
+		"name": {Name: "name", Type: "string", Required: true},
+	}, "vpc", "../modules/vpc")
+	formatter.Style = UsageStyleTerragrunt
+
+	output := formatter.FormatMarkdown()
+	if !strings.Contains(output, "```hcl\nterraform {\n  source = \"../modules/vpc\"\n}\n\ninputs = {\n") || strings.Contains(output, "module \"vpc\"") {
+		t.Errorf("Expected a terragrunt usage example\nActual output:\n%s", output)
+	}
+}
@@ -0,0 +1,82 @@
+package formatter
+
+import (
+	"strings"
+)
+
+// GenerateTfvarsDoc generates a starter variables file. Optional inputs are
+// set to their defaults, or commented out when the default is null, and
+// required inputs to their example value or an empty value of their type.
+// Sensitive inputs are always left empty, so the file can be committed before
+// it is filled in.
+func GenerateTfvarsDoc(module Module, opts Options) string {
+	formatter := NewUsageFormatter(module.Variables, module.Name, "")
+	formatter.SortBy = opts.SortBy
+	required, optional := formatter.separateVariables()
+
+	var sb strings.Builder
+	writeGroup := func(title string, variables []Variable, value func(Variable) string) {
+		if len(variables) == 0 {
+			return
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("# " + title + "\n")
+		for _, v := range variables {
+			sb.WriteString("\n")
+			if v.Description != "" {
+				for _, line := range strings.Split(strings.TrimSpace(v.Description), "\n") {
+					sb.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+				}
+			}
+			if v.Sensitive {
+				sb.WriteString("# Sensitive: set this value outside version control\n")
+				sb.WriteString(v.Name + " = " + emptyValue(v.Type) + "\n")
+				continue
+			}
+			if !v.Required && v.Default == nil {
+				// A null or unknown default is best left to the module
+				sb.WriteString("# " + v.Name + " = null\n")
+				continue
+			}
+			sb.WriteString(v.Name + " = " + value(v) + "\n")
+		}
+	}
+
+	writeGroup("Required inputs", required, func(v Variable) string {
+		if example, ok := opts.ExampleValues[v.Name]; ok {
+			return example
+		}
+		return emptyValue(v.Type)
+	})
+	if !opts.OmitOptionalInputs {
+		writeGroup("Optional inputs", optional, func(v Variable) string {
+			return formatHCLValue(v.Default)
+		})
+	}
+
+	return sb.String()
+}
+
+// emptyValue returns an empty value of the given type, used as a placeholder
+func emptyValue(typeStr string) string {
+	base := strings.TrimSpace(typeStr)
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+
+	switch base {
+	case "string":
+		return `""`
+	case "number":
+		return "0"
+	case "bool":
+		return "false"
+	case "list", "set", "tuple":
+		return "[]"
+	case "map", "object":
+		return "{}"
+	}
+	return "null"
+}
@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestGenerateTfvarsDoc(t *testing.T) {
+	module := Module{
+		Name: "database",
+		Variables: map[string]Variable{
+			"name":     {Name: "name", Type: "string", Description: "Name of the database\nUsed as a prefix", Required: true},
+			"port":     {Name: "port", Type: "number", Required: true},
+			"subnets":  {Name: "subnets", Type: "list(string)", Required: true},
+			"settings": {Name: "settings", Type: "object({...})", Required: true},
+			"password": {Name: "password", Type: "string", Description: "Master password", Required: true, Sensitive: true},
+			"size":     {Name: "size", Type: "string", Description: "Instance size", Default: "db.t3.micro"},
+			"tags":     {Name: "tags", Type: "map(string)", Default: map[string]interface{}{"team": "data"}},
+			"token":    {Name: "token", Type: "string", Default: "secret", Sensitive: true},
+			"comment":  {Name: "comment", Type: "string"},
+		},
+	}
+
+	expected := `# Required inputs
+
+# Name of the database
+# Used as a prefix
+name = "orders"
+
+# Master password
+# Sensitive: set this value outside version control
+password = ""
+
+port = 0
+
+settings = {}
+
+subnets = []
+
+# Optional inputs
+
+# comment = null
+
+# Instance size
+size = "db.t3.micro"
+
+tags = { team = "data" }
+
+# Sensitive: set this value outside version control
+token = ""
+`
+	output := GenerateTfvarsDoc(module, Options{ExampleValues: map[string]string{"name": `"orders"`, "password": `"hunter2"`}})
+	if output != expected {
+		t.Errorf("Unexpected tfvars output\nExpected:\n%s\nActual:\n%s", expected, output)
+	}
+
+	output = GenerateTfvarsDoc(module, Options{OmitOptionalInputs: true})
+	if expectedEnd := "subnets = []\n"; output[len(output)-len(expectedEnd):] != expectedEnd {
+		t.Errorf("Expected the optional inputs to be left out, got:\n%s", output)
+	}
+}
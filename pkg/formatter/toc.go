@@ -0,0 +1,113 @@
+package formatter
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// headingRegex matches an ATX heading of any level
+	headingRegex = regexp.MustCompile(`^(#{1,6}) +(.+?)(?: +#+)?\s*$`)
+	// slugRegex matches the characters GitHub drops from heading anchors
+	slugRegex = regexp.MustCompile(`[^\p{L}\p{N}\s_-]`)
+	// usageInputRegex matches an input set, or commented out, in a module block
+	// or inputs map
+	usageInputRegex = regexp.MustCompile(`^(  (?:# )?)([A-Za-z_][\w-]*)( *=)`)
+)
+
+// inputAnchor returns the anchor of an input's row in the inputs table, named
+// as terraform-docs names it
+func inputAnchor(name string) string {
+	return "input_" + name
+}
+
+// markdownHeading is a heading found in a markdown document
+type markdownHeading struct {
+	Level int
+	Title string
+}
+
+// markdownHeadings returns the headings of md outside code blocks
+func markdownHeadings(md string) []markdownHeading {
+	var headings []markdownHeading
+	inCode := false
+	for _, line := range strings.Split(md, "\n") {
+		switch {
+		case strings.HasPrefix(line, "```"):
+			inCode = !inCode
+			continue
+		case strings.HasPrefix(line, "<pre>"):
+			inCode = true
+		case strings.HasPrefix(line, "</code></pre>"):
+			inCode = false
+		}
+		if inCode {
+			continue
+		}
+		if match := headingRegex.FindStringSubmatch(line); match != nil {
+			headings = append(headings, markdownHeading{Level: len(match[1]), Title: match[2]})
+		}
+	}
+	return headings
+}
+
+// slugger assigns the anchors GitHub gives to headings, numbering repeated ones
+type slugger map[string]int
+
+func (s slugger) slug(title string) string {
+	slug := strings.ToLower(slugRegex.ReplaceAllString(title, ""))
+	slug = strings.ReplaceAll(strings.TrimSpace(slug), " ", "-")
+
+	count := s[slug]
+	s[slug] = count + 1
+	if count > 0 {
+		return fmt.Sprintf("%s-%d", slug, count)
+	}
+	return slug
+}
+
+// formatTableOfContents lists the level-two and level-three headings of body.
+// The headings of header come before the contents and are counted so that the
+// anchors of repeated titles match the ones GitHub assigns.
+func formatTableOfContents(header string, body string) string {
+	slugs := slugger{}
+	for _, heading := range markdownHeadings(header) {
+		slugs.slug(heading.Title)
+	}
+	slugs.slug("Contents")
+
+	var sb strings.Builder
+	for _, heading := range markdownHeadings(body) {
+		anchor := slugs.slug(heading.Title)
+		if heading.Level < 2 || heading.Level > 3 {
+			continue
+		}
+		indent := strings.Repeat("  ", heading.Level-2)
+		sb.WriteString(fmt.Sprintf("%s- [%s](#%s)\n", indent, heading.Title, anchor))
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "## Contents\n\n" + sb.String() + "\n"
+}
+
+// linkedCodeBlock renders code as an HTML code block in which the inputs set
+// at the top level of a module block or inputs map link to their rows in the
+// inputs table. Markdown code fences cannot contain links.
+func (f *UsageFormatter) linkedCodeBlock(code string) string {
+	lines := strings.Split(strings.TrimSuffix(html.EscapeString(code), "\n"), "\n")
+	for i, line := range lines {
+		match := usageInputRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if _, ok := f.Variables[match[2]]; !ok {
+			continue
+		}
+		link := fmt.Sprintf(`<a href="#%s">%s</a>`, inputAnchor(match[2]), match[2])
+		lines[i] = match[1] + link + line[len(match[1])+len(match[2]):]
+	}
+	return "<pre><code>" + strings.Join(lines, "\n") + "\n</code></pre>\n\n"
+}
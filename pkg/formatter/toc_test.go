@@ -0,0 +1,84 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownTableOfContents(t *testing.T) {
+	module := Module{
+		Name:     "vpc",
+		Header:   "# VPC\n\n## Outputs\n\n```hcl\n## Inputs\n```",
+		Markdown: "## Inputs\n\n| Name |\n\n## Outputs\n\n| Name |\n",
+		Variables: map[string]Variable{
+			"name": {Name: "name", Type: "string", Required: true},
+		},
+	}
+	opts := Options{
+		TOC: true,
+		Examples: []Example{
+			{Name: "Basic", Kind: ExampleDefault},
+			{Name: "Complete", Kind: ExampleComplete},
+		},
+	}
+
+	output := GenerateMarkdownDoc(module, "path/to/module", opts)
+	expected := "# VPC\n\n## Outputs\n\n```hcl\n## Inputs\n```\n\n## Contents\n\n" +
+		"- [Inputs](#inputs)\n" +
+		"- [Outputs](#outputs-1)\n" +
+		"- [Usage](#usage)\n" +
+		"  - [Basic](#basic)\n" +
+		"  - [Complete](#complete)\n\n## Inputs\n"
+	if !strings.HasPrefix(output, expected) {
+		t.Errorf("Unexpected table of contents\nExpected prefix:\n%s\nActual:\n%s", expected, output)
+	}
+}
+
+func TestSlugger(t *testing.T) {
+	slugs := slugger{}
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Inputs", "inputs"},
+		{"Usage with `for_each`", "usage-with-for_each"},
+		{"Inputs", "inputs-1"},
+		{"What's new?", "whats-new"},
+		{"Inputs", "inputs-2"},
+	}
+
+	for _, test := range tests {
+		if got := slugs.slug(test.title); got != test.expected {
+			t.Errorf("slug(%q) = %q; expected %q", test.title, got, test.expected)
+		}
+	}
+}
+
+func TestMarkdownAnchors(t *testing.T) {
+	module := Module{
+		Name: "app",
+		Variables: map[string]Variable{
+			"name":   {Name: "name", Type: "string", Required: true},
+			"policy": {Name: "policy", Type: "string", Default: "<none>"},
+		},
+	}
+
+	output := GenerateMarkdownDoc(module, "path/to/module", Options{Anchors: true, ShowDefaults: true})
+	for _, expected := range []string{
+		"| <a name=\"input_name\"></a> [name](#input_name) | string | yes |\n",
+		"<pre><code>module &#34;app&#34; {\n",
+		"  <a href=\"#input_name\">name</a>                = # string\n",
+		"  # <a href=\"#input_policy\">policy</a>               = &#34;&lt;none&gt;&#34;\n",
+		"}\n</code></pre>\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	// Without an inputs table there is nothing to link to
+	output = GenerateMarkdownDoc(module, "path/to/module", Options{Anchors: true, Sections: []string{SectionUsage}})
+	if !strings.Contains(output, "```hcl\n") || strings.Contains(output, "<a href") {
+		t.Errorf("Expected a plain code block without an inputs table, got:\n%s", output)
+	}
+}
@@ -0,0 +1,25 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateYAMLDoc generates YAML documentation with the same structure as
+// GenerateJSONDoc. Keys are sorted, so the output is stable between runs.
+func GenerateYAMLDoc(module Module, moduleSource string, opts Options) (string, error) {
+	var sb strings.Builder
+
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(structuredDoc(module, moduleSource, opts)); err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to generate YAML: %v", err)
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,65 @@
+package formatter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateYAMLDoc(t *testing.T) {
+	module := Module{
+		Name:   "network",
+		Path:   "modules/network",
+		Header: "# Network\n\nCreates a VPC.",
+		Variables: map[string]Variable{
+			"name":    {Name: "name", Type: "string", Required: true},
+			"comment": {Name: "comment", Type: "string", Default: nil},
+			"script":  {Name: "script", Type: "string", Default: "#!/bin/sh\necho hello\n"},
+			"flag":    {Name: "flag", Type: "string", Default: "true"},
+			"subnets": {Name: "subnets", Type: "map(object({ cidr = string }))", Default: map[string]interface{}{
+				"a": map[string]interface{}{"cidr": "10.0.0.0/24"},
+			}},
+		},
+		Outputs: []interface{}{map[string]interface{}{"name": "id", "description": "VPC ID", "value": nil}},
+	}
+
+	output, err := GenerateYAMLDoc(module, "./network", Options{})
+	if err != nil {
+		t.Fatalf("GenerateYAMLDoc failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"header: |-\n  # Network\n\n  Creates a VPC.\n",
+		"- default: null\n",
+		"default: |\n      #!/bin/sh\n      echo hello\n",
+		"default: \"true\"\n",
+		"default:\n      a:\n        cidr: 10.0.0.0/24\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q\nActual output:\n%s", expected, output)
+		}
+	}
+
+	// The YAML document carries the same data as the JSON one
+	jsonOutput, err := GenerateJSONDoc(module, "./network", Options{})
+	if err != nil {
+		t.Fatalf("GenerateJSONDoc failed: %v", err)
+	}
+	var fromJSON, fromYAML interface{}
+	if err := json.Unmarshal([]byte(jsonOutput), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(output), &fromYAML); err != nil {
+		t.Fatalf("Generated YAML does not parse: %v", err)
+	}
+	normalized, _ := json.Marshal(fromYAML)
+	if err := json.Unmarshal(normalized, &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Errorf("Expected the YAML document to match the JSON document\nJSON: %v\nYAML: %v", fromJSON, fromYAML)
+	}
+}
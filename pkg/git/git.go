@@ -0,0 +1,96 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run executes a git command in dir and returns its trimmed output
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s did not finish: %v", strings.Join(args, " "), ctx.Err())
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// TopLevel returns the root directory of the repository containing dir
+func TopLevel(ctx context.Context, dir string) (string, error) {
+	return run(ctx, dir, "rev-parse", "--show-toplevel")
+}
+
+// ResolveCommit returns the commit ref names in the repository containing dir.
+// A ref starting with "-" is rejected so that it cannot be read as an option.
+func ResolveCommit(ctx context.Context, dir string, ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref %q", ref)
+	}
+	commit, err := run(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || commit == "" {
+		return "", fmt.Errorf("git ref %q does not name a commit", ref)
+	}
+	return commit, nil
+}
+
+// ChangedFiles lists the tracked files that differ between ref and the
+// working tree of the repository containing dir, as absolute paths. Files
+// that were never committed or staged are not listed.
+func ChangedFiles(ctx context.Context, dir string, ref string) ([]string, error) {
+	top, err := TopLevel(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := ResolveCommit(ctx, top, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// The resolved commit cannot be mistaken for a path or an option
+	output, err := run(ctx, top, "diff", "--name-only", commit, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		files = append(files, filepath.Join(top, filepath.FromSlash(line)))
+	}
+
+	return files, nil
+}
+
+// RemoteURL returns the URL of the named remote of the repository containing dir
+func RemoteURL(ctx context.Context, dir string, remote string) (string, error) {
+	return run(ctx, dir, "remote", "get-url", remote)
+}
+
+// LatestTag returns the most recent tag reachable from HEAD in the repository
+// containing dir, or an empty string if there is none. Shallow clones often
+// have tags, but none reachable from HEAD.
+func LatestTag(ctx context.Context, dir string) (string, error) {
+	// Distinguish "no reachable tags" from other failures by listing them first
+	tags, err := run(ctx, dir, "tag", "--list")
+	if err != nil || tags == "" {
+		return "", err
+	}
+	reachable, err := run(ctx, dir, "tag", "--merged", "HEAD")
+	if err != nil || reachable == "" {
+		return "", err
+	}
+	return run(ctx, dir, "describe", "--tags", "--abbrev=0")
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newRepository creates a repository with one commit in a temporary
+// directory and returns it with a function running git in it
+func newRepository(t *testing.T) (string, func(args ...string)) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "tfdocs-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// git reports the real path of the repository
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "a" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	return dir, git
+}
+
+func TestLatestTag(t *testing.T) {
+	dir, git := newRepository(t)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	if tag, err := LatestTag(ctx, dir); err != nil || tag != "" {
+		t.Errorf("Expected no tag in a repository without tags, got %q (%v)", tag, err)
+	}
+
+	git("tag", "v1.0.0")
+	git("commit", "-q", "--allow-empty", "-m", "second")
+	if tag, err := LatestTag(ctx, dir); err != nil || tag != "v1.0.0" {
+		t.Errorf("Expected v1.0.0, got %q (%v)", tag, err)
+	}
+
+	// A tag that HEAD does not descend from, as in a shallow clone
+	git("checkout", "-q", "--orphan", "other")
+	git("commit", "-q", "--allow-empty", "-m", "unrelated")
+	if tag, err := LatestTag(ctx, dir); err != nil || tag != "" {
+		t.Errorf("Expected no tag when none is reachable from HEAD, got %q (%v)", tag, err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir, _ := newRepository(t)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "b" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Untracked files are not listed
+	if err := ioutil.WriteFile(filepath.Join(dir, "new.tf"), []byte(`variable "c" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ChangedFiles(ctx, dir, "HEAD")
+	if err != nil {
+		t.Fatalf("ChangedFiles failed: %v", err)
+	}
+	if expected := []string{filepath.Join(dir, "main.tf")}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	for _, ref := range []string{"", "--output=changes.txt", "missing"} {
+		if _, err := ChangedFiles(ctx, dir, ref); err == nil {
+			t.Errorf("Expected an error for the ref %q", ref)
+		}
+	}
+}
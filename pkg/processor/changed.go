@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/git"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// ChangedModules filters modules down to those affected by changes since ref
+// in the git repository containing root. A module is affected when one of its
+// files changed, or when it calls a local child module that is affected.
+func ChangedModules(ctx context.Context, root string, modules []string, ref string) ([]string, error) {
+	files, err := git.ChangedFiles(ctx, root, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %v", ref, err)
+	}
+
+	// Index modules by their resolved absolute path
+	byPath := make(map[string]string, len(modules))
+	for _, module := range modules {
+		abs, err := canonicalPath(module)
+		if err != nil {
+			return nil, err
+		}
+		byPath[abs] = module
+	}
+
+	// Map each changed file to the closest enclosing module
+	changed := make(map[string]bool)
+	for _, file := range files {
+		if dir := enclosingModule(filepath.Dir(file), byPath); dir != "" {
+			changed[dir] = true
+		}
+	}
+
+	// Record which local child modules each module calls
+	callers := make(map[string][]string)
+	for abs, module := range byPath {
+		calls, err := terraform.ParseModuleCalls(module)
+		if err != nil {
+			return nil, err
+		}
+		for _, call := range calls {
+			if !terraform.IsLocalSource(call.Source) {
+				continue
+			}
+			child, err := canonicalPath(filepath.Join(module, filepath.FromSlash(call.Source)))
+			if err != nil {
+				continue
+			}
+			callers[child] = append(callers[child], abs)
+		}
+	}
+
+	// Propagate changes from child modules to the modules that call them
+	queue := make([]string, 0, len(changed))
+	for dir := range changed {
+		queue = append(queue, dir)
+	}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		for _, caller := range callers[dir] {
+			if !changed[caller] {
+				changed[caller] = true
+				queue = append(queue, caller)
+			}
+		}
+	}
+
+	// Keep the original discovery order
+	var result []string
+	for _, module := range modules {
+		abs, _ := canonicalPath(module)
+		if changed[abs] {
+			result = append(result, module)
+		}
+	}
+
+	return result, nil
+}
+
+// enclosingModule returns the nearest directory at or above dir that is one
+// of the given modules, or an empty string if there is none
+func enclosingModule(dir string, modules map[string]string) string {
+	dir = canonicalDir(dir)
+	for {
+		if _, ok := modules[dir]; ok {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// canonicalPath returns the absolute path of path with symlinks resolved
+func canonicalPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// canonicalDir resolves dir like canonicalPath, falling back to the closest
+// existing ancestor for directories that were deleted by the change
+func canonicalDir(dir string) string {
+	var missing []string
+	for {
+		if resolved, err := canonicalPath(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Clean(strings.Join(append([]string{dir}, missing...), string(filepath.Separator)))
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+		dir = parent
+	}
+}
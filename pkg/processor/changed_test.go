@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedModules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "tfdocs-changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"root/main.tf":                 `module "app" { source = "./modules/app" }`,
+		"root/modules/app/main.tf":     `module "net" { source = "../network" }`,
+		"root/modules/network/main.tf": `variable "cidr" {}`,
+		"root/modules/other/main.tf":   `variable "x" {}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	// Change a file inside the network module, outside of its .tf files
+	if err := ioutil.WriteFile(filepath.Join(dir, "root/modules/network/templates.tpl"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+
+	root := filepath.Join(dir, "root")
+	modules, err := FindModules(context.Background(), root, DiscoveryOptions{})
+	if err != nil {
+		t.Fatalf("FindModules failed: %v", err)
+	}
+
+	changed, err := ChangedModules(context.Background(), root, modules, "HEAD")
+	if err != nil {
+		t.Fatalf("ChangedModules failed: %v", err)
+	}
+
+	expected := []string{
+		root,
+		filepath.Join(root, "modules/app"),
+		filepath.Join(root, "modules/network"),
+	}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected %v, got %v", expected, changed)
+	}
+
+	// A ref that looks like an option is rejected rather than passed to git
+	outputFile := filepath.Join(dir, "diff.out")
+	if _, err := ChangedModules(context.Background(), root, modules, "--output="+outputFile); err == nil {
+		t.Errorf("Expected an error for a ref starting with -")
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		t.Errorf("Expected git not to write %s", outputFile)
+	}
+
+	// A branch named like a directory is read as the branch
+	git("branch", "root")
+	if changed, err := ChangedModules(context.Background(), root, modules, "root"); err != nil || !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected %v for a ref that is also a path, got %v (%v)", expected, changed, err)
+	}
+}
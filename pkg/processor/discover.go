@@ -0,0 +1,136 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// skippedDirs lists directories that never contain modules to document
+var skippedDirs = map[string]bool{
+	".git":                     true,
+	".terraform":               true,
+	".terraform-docs-extended": true,
+}
+
+// DiscoveryOptions controls which directories FindModules returns.
+// Patterns are globs matched against slash-separated paths relative to the
+// root; "**" matches any number of directories and a pattern without a slash
+// matches a directory of that name at any depth.
+type DiscoveryOptions struct {
+	Include   []string
+	Exclude   []string
+	Gitignore bool
+}
+
+// FindModules walks root and returns every directory containing .tf files
+// that is selected by opts. The walk stops early when ctx is done.
+func FindModules(ctx context.Context, root string, opts DiscoveryOptions) ([]string, error) {
+	var modules []string
+
+	var ignore *gitignore
+	if opts.Gitignore {
+		var err error
+		if ignore, err = loadParentGitignores(root); err != nil {
+			return nil, err
+		}
+	}
+
+	err := filepath.Walk(root, func(dir string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Skip non-directories
+		if !info.IsDir() {
+			return nil
+		}
+
+		// Skip .git, .terraform and cache directories
+		if dir != root && skippedDirs[info.Name()] {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Skip excluded and git-ignored directories along with everything below them
+		if rel != "." && (matchAny(opts.Exclude, rel) || ignore.ignored(dir)) {
+			return filepath.SkipDir
+		}
+		if ignore != nil {
+			if err := ignore.load(dir); err != nil {
+				return err
+			}
+		}
+
+		// Only consider directories selected by the include patterns
+		if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+			return nil
+		}
+
+		// Check if this directory contains .tf files (potential module)
+		files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			modules = append(modules, dir)
+		}
+
+		return nil
+	})
+
+	return modules, err
+}
+
+// matchAny reports whether rel matches any of the glob patterns
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated relative path against a glob pattern
+// supporting "**" segments
+func matchGlob(pattern string, rel string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try every possible number of directories for "**"
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-discover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, module := range []string{
+		".",
+		"modules/vpc",
+		"modules/vpc/examples/basic",
+		"modules/ecs",
+		"test/fixtures/simple",
+		"vendor/thirdparty",
+		".terraform/modules/cached",
+	} {
+		path := filepath.Join(dir, module)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(path, "main.tf"), []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".gitignore"), []byte("# vendored code\nvendor/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rel := func(modules []string) []string {
+		var result []string
+		for _, module := range modules {
+			r, _ := filepath.Rel(dir, module)
+			result = append(result, filepath.ToSlash(r))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		opts     DiscoveryOptions
+		expected []string
+	}{
+		{
+			"All modules",
+			DiscoveryOptions{},
+			[]string{".", "modules/ecs", "modules/vpc", "modules/vpc/examples/basic", "test/fixtures/simple", "vendor/thirdparty"},
+		},
+		{
+			"Gitignore",
+			DiscoveryOptions{Gitignore: true},
+			[]string{".", "modules/ecs", "modules/vpc", "modules/vpc/examples/basic", "test/fixtures/simple"},
+		},
+		{
+			"Exclude by name and path",
+			DiscoveryOptions{Exclude: []string{"examples", "test/**"}, Gitignore: true},
+			[]string{".", "modules/ecs", "modules/vpc"},
+		},
+		{
+			"Include",
+			DiscoveryOptions{Include: []string{"modules/*"}},
+			[]string{"modules/ecs", "modules/vpc"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modules, err := FindModules(context.Background(), dir, test.opts)
+			if err != nil {
+				t.Fatalf("FindModules failed: %v", err)
+			}
+			if got := rel(modules); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"examples", "examples", true},
+		{"examples", "modules/vpc/examples", true},
+		{"examples/*", "examples/basic", true},
+		{"modules/*", "modules/vpc/sub", false},
+		{"modules/**", "modules/vpc/sub", true},
+		{"**/fixtures", "test/fixtures", true},
+		{"./test", "test", true},
+		{"test", "tests", false},
+	}
+
+	for _, test := range tests {
+		if got := matchGlob(test.pattern, test.path); got != test.expected {
+			t.Errorf("matchGlob(%q, %q) = %v, expected %v", test.pattern, test.path, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/formatter"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// exampleTitles are the headings of the generated examples
+var exampleTitles = map[string]string{
+	formatter.ExampleDefault:  "Default",
+	formatter.ExampleMinimal:  "Minimal",
+	formatter.ExampleComplete: "Complete",
+}
+
+// moduleExamples builds the usage examples configured for the module at path.
+// It returns nil when no examples are configured, which keeps the single
+// default usage block.
+func moduleExamples(path string, usage config.Usage, moduleSource string, moduleVersion string) ([]formatter.Example, error) {
+	if len(usage.Examples) == 0 && len(usage.Scenarios) == 0 && usage.ExamplesDir == "" {
+		return nil, nil
+	}
+
+	var examples []formatter.Example
+
+	kinds := usage.Examples
+	if len(kinds) == 0 {
+		kinds = []string{formatter.ExampleDefault}
+	}
+	for _, kind := range kinds {
+		if !formatter.IsExampleKind(kind) {
+			return nil, fmt.Errorf("unknown usage example: %s. Must be one of: %s", kind, strings.Join(formatter.ExampleKinds, ", "))
+		}
+		examples = append(examples, formatter.Example{Name: exampleTitles[kind], Kind: kind})
+	}
+
+	for _, scenario := range usage.Scenarios {
+		if scenario.Name == "" {
+			return nil, fmt.Errorf("usage scenarios must have a name")
+		}
+		examples = append(examples, formatter.Example{
+			Name:        scenario.Name,
+			Description: scenario.Description,
+			Values:      scenario.Values,
+		})
+	}
+
+	if usage.ExamplesDir != "" {
+		embedded, err := directoryExamples(path, filepath.Join(path, usage.ExamplesDir), moduleSource, moduleVersion)
+		if err != nil {
+			return nil, err
+		}
+		examples = append(examples, embedded...)
+	}
+
+	return examples, nil
+}
+
+// directoryExamples reads the main.tf of every subdirectory of dir, named
+// after the subdirectory. A missing directory yields no examples.
+func directoryExamples(modulePath string, dir string, moduleSource string, moduleVersion string) ([]formatter.Example, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read examples: %v", err)
+	}
+
+	var examples []formatter.Example
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		exampleDir := filepath.Join(dir, entry.Name())
+		content, err := ioutil.ReadFile(filepath.Join(exampleDir, "main.tf"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read example %s: %v", entry.Name(), err)
+		}
+
+		examples = append(examples, formatter.Example{
+			Name: entry.Name(),
+			Code: rewriteModuleSources(string(content), exampleDir, modulePath, moduleSource, moduleVersion),
+		})
+	}
+
+	return examples, nil
+}
+
+var sourceAttributeRegex = regexp.MustCompile(`(?m)^([ \t]*)(source\s*=\s*)"([^"]*)"`)
+
+// rewriteModuleSources replaces the local sources in content that point from
+// exampleDir to modulePath with moduleSource, the source users of the module
+// should use. A registry source is pinned to moduleVersion when it is known.
+func rewriteModuleSources(content string, exampleDir string, modulePath string, moduleSource string, moduleVersion string) string {
+	target := canonicalDir(modulePath)
+
+	return sourceAttributeRegex.ReplaceAllStringFunc(content, func(match string) string {
+		parts := sourceAttributeRegex.FindStringSubmatch(match)
+		indent, attribute, source := parts[1], parts[2], parts[3]
+		if !terraform.IsLocalSource(source) || canonicalDir(filepath.Join(exampleDir, source)) != target {
+			return match
+		}
+		if moduleVersion == "" || !terraform.IsRegistryAddress(moduleSource) {
+			return indent + attribute + strconv.Quote(moduleSource)
+		}
+		// Aligned the way terraform fmt aligns the two arguments
+		return indent + "source  = " + strconv.Quote(moduleSource) + "\n" + indent + "version = " + strconv.Quote(moduleVersion)
+	})
+}
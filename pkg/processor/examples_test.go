@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/formatter"
+)
+
+func TestModuleExamples(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-examples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.tf": `variable "name" {}`,
+		"examples/basic/main.tf": `module "app" {
+  source = "../.."
+  name   = "basic"
+}
+
+module "other" {
+  source = "../../../other"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+`,
+		"examples/notes/README.md": "Not an example",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage := config.Usage{
+		Examples:    []string{"minimal", "complete"},
+		Scenarios:   []config.Scenario{{Name: "Named", Values: map[string]string{"name": `"x"`}}},
+		ExamplesDir: "examples",
+	}
+	examples, err := moduleExamples(dir, usage, "app.terraform.io/org/app/aws", "1.2.0")
+	if err != nil {
+		t.Fatalf("moduleExamples failed: %v", err)
+	}
+
+	var names []string
+	for _, example := range examples {
+		names = append(names, example.Name)
+	}
+	if strings.Join(names, ",") != "Minimal,Complete,Named,basic" {
+		t.Fatalf("Unexpected examples: %v", names)
+	}
+	if examples[0].Kind != formatter.ExampleMinimal || examples[2].Kind != "" {
+		t.Errorf("Unexpected example kinds: %+v", examples)
+	}
+
+	code := examples[3].Code
+	for _, expected := range []string{"  source  = \"app.terraform.io/org/app/aws\"\n  version = \"1.2.0\"\n  name", `source = "../../../other"`, `source = "hashicorp/aws"`} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected the embedded example to contain %q, got:\n%s", expected, code)
+		}
+	}
+
+	// No configured examples keep the single default usage block
+	if examples, err := moduleExamples(dir, config.Usage{}, "x", ""); err != nil || examples != nil {
+		t.Errorf("Expected no examples, got %v (%v)", examples, err)
+	}
+	if _, err := moduleExamples(dir, config.Usage{Examples: []string{"maximal"}}, "x", ""); err == nil {
+		t.Errorf("Expected an error for an unknown example kind")
+	}
+}
@@ -0,0 +1,314 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/cache"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/formatter"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/source"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// Options configures a documentation run started with Generate
+type Options struct {
+	// Path is the module directory, or the root directory in recursive mode
+	Path string
+	// Recursive processes every module found below Path
+	Recursive bool
+	// Discovery selects the modules found in recursive mode
+	Discovery DiscoveryOptions
+	// Modules, when set, replaces discovery with an explicit list of module directories
+	Modules []string
+	// ChangedSince limits processing to modules affected by changes since this git ref
+	ChangedSince string
+
+	// Config is the project configuration. A module directory containing its own
+	// configuration file overrides it for that module. Overrides, such as the
+	// settings given on the command line, and the fields below take precedence
+	// over both when set.
+	Config    config.Config
+	Overrides config.Config
+
+	// Format is the output format; it defaults to "markdown"
+	Format string
+	// Output is the output path template; see ResolveOutputPath. When empty, a
+	// single module is written to Stdout and recursive runs use DefaultOutputTemplate.
+	Output string
+	// ModuleName and ModuleSource are used in the usage example; they default
+	// to "example" and "path/to/module"
+	ModuleName   string
+	ModuleSource string
+
+	// Timeout bounds the terraform-docs runs that extract each module's
+	// information, together; zero means no limit
+	Timeout time.Duration
+
+	// Cache, when non-nil, serves unchanged modules from previously rendered output
+	Cache *cache.Cache
+
+	// Stdout receives documentation that is not written to a file; nil discards it
+	Stdout io.Writer
+	// Diagnostics receives progress messages, warnings and errors as they are
+	// produced. When nil, they are printed as plain text to Log, and discarded
+	// if Log is nil too.
+	Diagnostics diag.Handler
+	Log         io.Writer
+}
+
+// ModuleResult describes the documentation generated for a single module
+type ModuleResult struct {
+	Path       string
+	Name       string
+	OutputPath string
+	Content    string
+	Cached     bool
+	// Status is StatusUpdated or StatusUnchanged for modules written to a
+	// file, and StatusStdout otherwise
+	Status string
+}
+
+// Result describes the outcome of a documentation run
+type Result struct {
+	Modules []ModuleResult
+	// Diagnostics holds the warnings and errors reported during the run
+	Diagnostics diag.Diagnostics
+}
+
+// Count returns the number of modules with the given output status
+func (r Result) Count(status string) int {
+	count := 0
+	for _, module := range r.Modules {
+		if module.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Generate produces documentation for the modules selected by opts.
+// Failures that prevent the run as a whole are returned as errors, while a module
+// that cannot be documented is reported as an error diagnostic and the run continues.
+// Generate writes only to the files and writers given in opts.
+// When ctx is done, running subprocesses are killed and no further files are written.
+func Generate(ctx context.Context, opts Options) (Result, error) {
+	g := newGenerator(opts)
+	for _, format := range []string{g.opts.Format, g.opts.Config.Format, g.opts.Overrides.Format} {
+		if format != "" && !formatter.IsSupportedFormat(format) {
+			return g.result, fmt.Errorf("unsupported output format: %s", format)
+		}
+	}
+	for _, kind := range []string{g.opts.Config.Usage.InferSource, g.opts.Overrides.Usage.InferSource} {
+		if kind != "" && !source.IsValidKind(kind) {
+			return g.result, fmt.Errorf("unsupported source inference: %s", kind)
+		}
+	}
+	modules, err := SelectModules(ctx, g.opts)
+	if err != nil {
+		return g.result, err
+	}
+	if len(modules) == 0 && g.opts.ChangedSince != "" {
+		g.infof("No modules changed since %s", g.opts.ChangedSince)
+	}
+
+	settings, err := g.resolveSettings(ctx, modules)
+	if err != nil {
+		return g.result, err
+	}
+
+	for i, path := range modules {
+		if err := ctx.Err(); err != nil {
+			return g.result, err
+		}
+
+		module, err := g.processModule(ctx, path, settings[i])
+		if err != nil {
+			if ctx.Err() != nil {
+				return g.result, ctx.Err()
+			}
+			g.report(diag.Diagnostic{Severity: diag.Error, Module: path, Message: err.Error()})
+			continue
+		}
+		g.result.Modules = append(g.result.Modules, module)
+	}
+
+	// Summarise what happened to the output files
+	if updated, unchanged := g.result.Count(StatusUpdated), g.result.Count(StatusUnchanged); updated+unchanged > 0 {
+		g.infof("%d file(s) updated, %d unchanged", updated, unchanged)
+	}
+
+	return g.result, nil
+}
+
+// SelectModules returns the module directories a run with opts would process
+func SelectModules(ctx context.Context, opts Options) ([]string, error) {
+	modules := opts.Modules
+	if modules == nil {
+		modules = []string{opts.Path}
+		if opts.Recursive {
+			var err error
+			if modules, err = FindModules(ctx, opts.Path, opts.Discovery); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if opts.ChangedSince != "" {
+		return ChangedModules(ctx, opts.Path, modules, opts.ChangedSince)
+	}
+
+	return modules, nil
+}
+
+// withTimeout derives a context limited to timeout, or without a limit when it is zero
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// generator carries the state of a single Generate call
+type generator struct {
+	opts    Options
+	result  Result
+	sources *source.Resolver
+}
+
+// newGenerator fills in the default writers and diagnostics handler of opts
+func newGenerator(opts Options) *generator {
+	g := &generator{opts: opts, sources: source.NewResolver()}
+	if g.opts.Stdout == nil {
+		g.opts.Stdout = ioutil.Discard
+	}
+	if g.opts.Diagnostics == nil {
+		log := g.opts.Log
+		if log == nil {
+			log = ioutil.Discard
+		}
+		g.opts.Diagnostics = diag.NewTextPrinter(log, diag.Info, false)
+	}
+	return g
+}
+
+// report passes a diagnostic to the handler and records warnings and errors in the result
+func (g *generator) report(d diag.Diagnostic) {
+	if d.Severity > diag.Info {
+		g.result.Diagnostics = append(g.result.Diagnostics, d)
+	}
+	g.opts.Diagnostics.Handle(d)
+}
+
+// infof reports a progress message
+func (g *generator) infof(format string, args ...interface{}) {
+	g.report(diag.Diagnostic{Severity: diag.Info, Message: fmt.Sprintf(format, args...)})
+}
+
+// processModule renders the documentation for one module and writes it to
+// the output path in its settings, or to Stdout when that is empty
+func (g *generator) processModule(ctx context.Context, path string, s moduleSettings) (ModuleResult, error) {
+	outputPath := s.OutputPath
+	result := ModuleResult{
+		Path:       path,
+		Name:       s.Name,
+		OutputPath: outputPath,
+	}
+
+	// Look up previously rendered output for this module
+	c := g.opts.Cache
+	var cacheKey string
+	if c != nil {
+		// The cache is shared by every module of every project, and the
+		// output depends on the terraform-docs release that rendered it
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return result, fmt.Errorf("failed to compute cache key: %v", err)
+		}
+		params := append([]string{absPath, terraform.DocsVersion(ctx)}, s.cacheParams()...)
+		key, err := c.Key(path, params...)
+		if err != nil {
+			return result, fmt.Errorf("failed to compute cache key: %v", err)
+		}
+		cacheKey = key
+		result.Content, result.Cached = c.Get(cacheKey)
+	}
+
+	if result.Cached {
+		g.infof("Module unchanged, using cached output: %s", path)
+	} else {
+		g.infof("Processing module: %s", path)
+
+		// Extract module information, bounding the time spent in terraform-docs
+		extractCtx, cancel := withTimeout(ctx, g.opts.Timeout)
+		module, diags, err := ExtractModuleInfo(extractCtx, path, result.Name)
+		cancel()
+		if err != nil {
+			return result, fmt.Errorf("failed to extract module info: %v", err)
+		}
+
+		// An interrupted run must not write documentation built from partial information
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		for _, d := range diags {
+			g.report(d)
+		}
+
+		// Generate the documentation with our extended usage section
+		result.Content, err = formatter.GenerateDoc(module, s.Format, s.Source, s.Doc)
+		if err != nil {
+			return result, fmt.Errorf("failed to generate documentation for %s: %v", path, err)
+		}
+
+		// Only cache complete output; a module rendered with warnings
+		// should be retried on the next run
+		if cacheKey != "" && !diags.HasAtLeast(diag.Warning) {
+			if err := c.Put(cacheKey, result.Content); err != nil {
+				g.report(diag.Diagnostic{Severity: diag.Warning, Module: path, Message: fmt.Sprintf("Failed to update cache: %v", err)})
+			}
+		}
+	}
+
+	// Output the documentation
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	if outputPath != "" {
+		content := result.Content
+		if s.InjectTemplate != "" {
+			existing, err := ioutil.ReadFile(outputPath)
+			if err != nil && !os.IsNotExist(err) {
+				return result, fmt.Errorf("failed to read output file: %v", err)
+			}
+			if content, err = injectContent(string(existing), content, s.InjectTemplate); err != nil {
+				return result, err
+			}
+		}
+
+		written, err := writeFileAtomic(outputPath, []byte(content))
+		if err != nil {
+			return result, fmt.Errorf("failed to write output file: %v", err)
+		}
+		if written {
+			result.Status = StatusUpdated
+			g.infof("Documentation written to: %s", outputPath)
+		} else {
+			result.Status = StatusUnchanged
+			g.infof("Documentation unchanged: %s", outputPath)
+		}
+	} else {
+		result.Status = StatusStdout
+		if _, err := fmt.Fprintln(g.opts.Stdout, result.Content); err != nil {
+			return result, fmt.Errorf("failed to write documentation: %v", err)
+		}
+	}
+
+	return result, nil
+}
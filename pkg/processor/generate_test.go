@@ -0,0 +1,524 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
+)
+
+func TestGenerateWithoutTerraformDocs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := `
+variable "name" {
+  description = "Name of the thing"
+  type        = string
+}
+
+variable "size" {
+  type    = number
+  default = 1
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "variables.tf"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hide terraform-docs so generation falls back to direct parsing
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", oldPath)
+
+	var stdout, log bytes.Buffer
+	result, err := Generate(context.Background(), Options{
+		Path:         dir,
+		ModuleName:   "thing",
+		ModuleSource: "./modules/thing",
+		Stdout:       &stdout,
+		Log:          &log,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(result.Modules) != 1 || result.Modules[0].Name != "thing" {
+		t.Fatalf("Expected a single module result, got %+v", result.Modules)
+	}
+	if !result.Diagnostics.HasAtLeast(diag.Warning) || result.Diagnostics.HasAtLeast(diag.Error) {
+		t.Errorf("Expected only a warning about terraform-docs being unavailable, got %+v", result.Diagnostics)
+	} else if result.Diagnostics[0].Module != dir {
+		t.Errorf("Expected the warning to name the module, got %+v", result.Diagnostics[0])
+	}
+	if !strings.Contains(log.String(), "Warning:") {
+		t.Errorf("Expected warnings to be written to the log writer, got:\n%s", log.String())
+	}
+
+	for _, expected := range []string{"## Usage", `module "thing" {`, `source  = "./modules/thing"`, "  name", "  # size"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout.String())
+		}
+	}
+	if result.Modules[0].Content == "" {
+		t.Errorf("Expected the rendered content to be returned in the result")
+	}
+}
+
+func TestGenerateRejectsUnknownFormat(t *testing.T) {
+	if _, err := Generate(context.Background(), Options{Path: ".", Format: "docx"}); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestGenerateRejectsUnknownSection(t *testing.T) {
+	cfg := config.Config{Sections: []string{"usage", "changelog"}}
+	_, err := Generate(context.Background(), Options{Path: ".", Config: cfg})
+	if err == nil || !strings.Contains(err.Error(), "unknown section") {
+		t.Errorf("Expected an error for an unknown section, got %v", err)
+	}
+}
+
+func TestGenerateTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell script stand-in for terraform-docs")
+	}
+
+	dir, err := ioutil.TempDir("", "tfdocs-timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A terraform-docs that never finishes
+	script := "#!/bin/sh\nexec sleep 30\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "terraform-docs"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "a" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath)
+	defer os.Setenv("PATH", oldPath)
+
+	start := time.Now()
+	output := filepath.Join(dir, "README.md")
+	result, err := Generate(context.Background(), Options{Path: dir, Output: output, Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected terraform-docs to be killed after the timeout, took %v", elapsed)
+	}
+	if !result.Diagnostics.HasAtLeast(diag.Error) || !strings.Contains(result.Diagnostics[0].Message, "timed out") {
+		t.Errorf("Expected a timeout error, got %+v", result.Diagnostics)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file to be written after a timeout")
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-cancel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "a" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := filepath.Join(dir, "README.md")
+	if _, err := Generate(ctx, Options{Path: dir, Output: output}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file to be written after cancellation")
+	}
+}
+
+func TestGenerateAppliesConfig(t *testing.T) {
+	root, err := ioutil.TempDir("", "tfdocs-generate-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{"network", "storage"} {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "variable \"region\" {\n  type = string\n}\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The storage module overrides the project settings with its own file
+	override := "usage:\n  example_values:\n    region: '\"us-east-1\"'\n"
+	if err := ioutil.WriteFile(filepath.Join(root, "storage", config.FileName), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hide terraform-docs so generation falls back to direct parsing
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", root)
+	defer os.Setenv("PATH", oldPath)
+
+	result, err := Generate(context.Background(), Options{
+		Path:      root,
+		Recursive: true,
+		Config: config.Config{
+			Output: "{{.ModuleDir}}/USAGE.{{.Ext}}",
+			Usage: config.Usage{
+				Source:        "git::https://example.com/infra.git//{{.RelDir}}",
+				ExampleValues: map[string]string{"region": `"eu-west-1"`},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Modules) != 2 {
+		t.Fatalf("Expected two modules, got %+v", result.Modules)
+	}
+
+	expected := map[string][]string{
+		"network": {`source  = "git::https://example.com/infra.git//network"`, `region                = "eu-west-1"`},
+		"storage": {`source  = "git::https://example.com/infra.git//storage"`, `region                = "us-east-1"`},
+	}
+	for name, lines := range expected {
+		content, err := ioutil.ReadFile(filepath.Join(root, name, "USAGE.md"))
+		if err != nil {
+			t.Fatalf("Expected the configured output path to be written: %v", err)
+		}
+		for _, line := range lines {
+			if !strings.Contains(string(content), line) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", name, line, content)
+			}
+		}
+	}
+
+	// Options set explicitly take precedence over the configuration
+	result, err = Generate(context.Background(), Options{
+		Path:         filepath.Join(root, "network"),
+		Config:       config.Config{Usage: config.Usage{Source: "ignored"}},
+		ModuleSource: "./network",
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(result.Modules[0].Content, `source  = "./network"`) {
+		t.Errorf("Expected the explicit module source to win, got:\n%s", result.Modules[0].Content)
+	}
+
+	// Overrides, such as command line flags, beat the module's own file
+	style := "usage:\n  style: module\n"
+	if err := ioutil.WriteFile(filepath.Join(root, "storage", config.FileName), []byte(style), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = Generate(context.Background(), Options{
+		Path:      filepath.Join(root, "storage"),
+		Overrides: config.Config{Usage: config.Usage{Style: "terragrunt"}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if content := result.Modules[0].Content; strings.Contains(content, `module "example" {`) || !strings.Contains(content, "terraform {") {
+		t.Errorf("Expected the override to beat the module configuration file, got:\n%s", content)
+	}
+}
+
+func TestGenerateHonoursTerraformDocsConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-generate-tfdocs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		".terraform-docs.yml": "header-from: HEADER.md\nsections:\n  hide: [footer]\nsettings:\n  default: false\noutput:\n  file: README.md\ncontent: '{{ .Inputs }}'\n",
+		"HEADER.md":           "# Network",
+		"README.md":           "Intro\n\n<!-- BEGIN_TF_DOCS -->\n<!-- END_TF_DOCS -->\n",
+		"main.tf":             "variable \"size\" {\n  type    = number\n  default = 3\n}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Hide terraform-docs so generation falls back to direct parsing
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", oldPath)
+
+	// A single module is written to Stdout, whatever output.file says
+	var stdout bytes.Buffer
+	result, err := Generate(context.Background(), Options{Path: dir, Stdout: &stdout})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if result.Modules[0].OutputPath != "" || !strings.Contains(stdout.String(), "# Network") {
+		t.Fatalf("Expected a single module to be written to stdout, got %q", result.Modules[0].OutputPath)
+	}
+
+	// Unsupported settings are reported where they are set
+	warned := false
+	for _, d := range result.Diagnostics {
+		if d.Severity == diag.Warning && strings.Contains(d.Message, `"content"`) && d.Line == 8 {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("Expected a warning for the unsupported content setting, got %+v", result.Diagnostics)
+	}
+
+	// settings.default in the terraform-docs configuration overrides show_defaults
+	showDefaults := true
+	result, err = Generate(context.Background(), Options{
+		Path:      dir,
+		Recursive: true,
+		Config:    config.Config{Usage: config.Usage{ShowDefaults: &showDefaults}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(result.Modules) != 1 || result.Modules[0].OutputPath != filepath.Join(dir, "README.md") {
+		t.Fatalf("Expected output.file to be used in recursive mode, got %+v", result.Modules)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Intro\n\n<!-- BEGIN_TF_DOCS -->\n# Network", "# size               = number", "<!-- END_TF_DOCS -->"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected README.md to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestGenerateTfvars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-generate-tfvars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := `
+variable "password" {
+  type      = string
+  sensitive = true
+}
+
+variable "size" {
+  type    = number
+  default = 1
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "variables.tf"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hide terraform-docs so generation falls back to direct parsing
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", oldPath)
+
+	optional := false
+	result, err := Generate(context.Background(), Options{
+		Path:   dir,
+		Format: "tfvars",
+		Config: config.Config{Tfvars: config.Tfvars{Optional: &optional}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := "# Required inputs\n\n# Sensitive: set this value outside version control\npassword = \"\"\n"
+	if result.Modules[0].Content != expected {
+		t.Errorf("Expected:\n%s\nActual:\n%s", expected, result.Modules[0].Content)
+	}
+}
+
+func TestGenerateGroupsInputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-generate-groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := `
+variable "vpc_id" {
+  description = "[Networking] VPC to deploy into"
+  type        = string
+}
+
+variable "bucket" {
+  type = string
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "variables.tf"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hide terraform-docs so generation falls back to direct parsing
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", oldPath)
+
+	result, err := Generate(context.Background(), Options{
+		Path:   dir,
+		Format: "json",
+		Config: config.Config{InputGroups: []config.InputGroup{{Name: "Storage", Inputs: []string{"buck*"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	for _, expected := range []string{`"group": "Networking"`, `"group": "Storage"`} {
+		if !strings.Contains(result.Modules[0].Content, expected) {
+			t.Errorf("Expected output to contain %s\nActual:\n%s", expected, result.Modules[0].Content)
+		}
+	}
+
+	bad := config.Config{InputGroups: []config.InputGroup{{Name: "Storage", Inputs: []string{"[bucket"}}}}
+	if _, err := Generate(context.Background(), Options{Path: dir, Config: bad}); err == nil {
+		t.Errorf("Expected an error for an invalid input pattern")
+	}
+}
+
+func TestGenerateJSONSchemaMultilineObject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-generate-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := `
+variable "settings" {
+  type = object({
+    name = string
+    size = optional(number)
+  })
+}
+
+variable "broken" {
+  type = object({ name = string size = number })
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "variables.tf"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hide terraform-docs so generation falls back to direct parsing
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", oldPath)
+
+	result, err := Generate(context.Background(), Options{Path: dir, Format: "jsonschema"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	var schema struct {
+		Properties map[string]struct {
+			Type       string                 `json:"type"`
+			Properties map[string]interface{} `json:"properties"`
+			Required   []string               `json:"required"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(result.Modules[0].Content), &schema); err != nil {
+		t.Fatalf("Generated schema is not valid JSON: %v", err)
+	}
+	settings := schema.Properties["settings"]
+	if settings.Type != "object" || len(settings.Properties) != 2 || !reflect.DeepEqual(settings.Required, []string{"name"}) {
+		t.Errorf("Unexpected schema for a multi-line object type:\n%s", result.Modules[0].Content)
+	}
+
+	warned := false
+	for _, d := range result.Diagnostics {
+		if d.Severity == diag.Warning && strings.Contains(d.Message, `input "broken"`) {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("Expected a warning for the type that cannot be parsed, got %+v", result.Diagnostics)
+	}
+}
+
+func TestGenerateWithTerraformDocsRequiredInputs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell script stand-in for terraform-docs")
+	}
+
+	dir, err := ioutil.TempDir("", "tfdocs-generate-required")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// terraform-docs reports a null default for required inputs
+	docs := `{"inputs": [
+  {"name": "region", "type": "string", "description": "", "default": null, "required": true},
+  {"name": "size", "type": "number", "description": "", "default": 1, "required": false}
+]}`
+	script := "#!/bin/sh\nif [ \"$1\" = json ]; then cat \"" + filepath.Join(dir, "docs.json") + "\"; fi\n"
+	files := map[string]string{
+		"terraform-docs": script,
+		"docs.json":      docs,
+		"main.tf":        "variable \"region\" {\n  type = string\n}\n\nvariable \"size\" {\n  type    = number\n  default = 1\n}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath)
+	defer os.Setenv("PATH", oldPath)
+
+	result, err := Generate(context.Background(), Options{Path: dir, Format: "tfvars"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if content := result.Modules[0].Content; !strings.HasPrefix(content, "# Required inputs\n\nregion = \"\"\n") {
+		t.Errorf("Expected region to be a required input, got:\n%s", content)
+	}
+
+	result, err = Generate(context.Background(), Options{Path: dir, Format: "jsonschema"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var schema struct {
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal([]byte(result.Modules[0].Content), &schema); err != nil {
+		t.Fatalf("Generated schema is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"region"}) {
+		t.Errorf("Expected region to be required, got %v", schema.Required)
+	}
+}
@@ -0,0 +1,135 @@
+package processor
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreRule is a single pattern read from a .gitignore file
+type gitignoreRule struct {
+	base     string
+	pattern  string
+	negate   bool
+	anchored bool
+}
+
+// gitignore holds the rules of every .gitignore file seen so far
+type gitignore struct {
+	rules []gitignoreRule
+}
+
+// loadParentGitignores reads the .gitignore files from the repository root
+// down to (but excluding) dir, so rules defined above dir also apply to it
+func loadParentGitignores(dir string) (*gitignore, error) {
+	ignore := &gitignore{}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var parents []string
+	for current := abs; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			// Not inside a repository; only nested .gitignore files apply
+			parents = nil
+			break
+		}
+		parents = append([]string{parent}, parents...)
+		current = parent
+	}
+
+	for _, parent := range parents {
+		if err := ignore.load(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	return ignore, nil
+}
+
+// load adds the rules from the .gitignore file in dir, if there is one
+func (g *gitignore) load(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(filepath.Join(abs, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := gitignoreRule{base: abs}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		// Only directories are matched, so directory-only rules need no special handling
+		line = strings.TrimRight(line, "/")
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+
+		g.rules = append(g.rules, rule)
+	}
+
+	return scanner.Err()
+}
+
+// ignored reports whether the directory dir is excluded by the loaded rules.
+// As in git, the last matching rule wins.
+func (g *gitignore) ignored(dir string) bool {
+	if g == nil {
+		return false
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+
+	ignored := false
+	for _, rule := range g.rules {
+		rel, err := filepath.Rel(rule.base, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		var matched bool
+		if rule.anchored {
+			matched = matchSegments(strings.Split(rule.pattern, "/"), strings.Split(rel, "/"))
+		} else {
+			matched = matchSegments([]string{"**", rule.pattern}, strings.Split(rel, "/"))
+		}
+		if matched {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/source"
+)
+
+// DefaultOutputTemplate is the output path used for each module in recursive mode
+const DefaultOutputTemplate = "{{.ModuleDir}}/README.{{.Ext}}"
+
+// FormatExtensions maps each output format to its file extension
+var FormatExtensions = map[string]string{
+	"markdown":   "md",
+	"json":       "json",
+	"yaml":       "yaml",
+	"asciidoc":   "adoc",
+	"html":       "html",
+	"tfvars":     "tfvars",
+	"jsonschema": "schema.json",
+	"terragrunt": "hcl",
+	"mermaid":    "mmd",
+}
+
+// OutputPathData holds the values available to an output path template
+type OutputPathData struct {
+	// ModuleDir is the module directory as found during discovery
+	ModuleDir string
+	// ModuleName is the name used for the module in the usage example; in a
+	// usage name template it is the name inferred from the directory
+	ModuleName string
+	// DirName is the base name of the module directory
+	DirName string
+	// RelDir is the module directory relative to the root being processed
+	RelDir string
+	// Format is the output format and Ext its file extension
+	Format string
+	Ext    string
+}
+
+// ResolveOutputPath expands an output path template such as
+// "docs/{{.ModuleName}}.{{.Ext}}" for a single module
+func ResolveOutputPath(pathTemplate string, data OutputPathData) (string, error) {
+	if !strings.Contains(pathTemplate, "{{") {
+		return pathTemplate, nil
+	}
+
+	path, err := expandTemplate("output path", pathTemplate, data)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
+
+// templateFuncs are the functions available in output path and usage templates
+var templateFuncs = template.FuncMap{
+	"identifier": source.Identifier,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+// expandTemplate expands a template over the values of a module; what names
+// the setting in error messages
+func expandTemplate(what string, text string, data OutputPathData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(what).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template %q: %v", what, text, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to expand %s template %q: %v", what, text, err)
+	}
+
+	return sb.String(), nil
+}
+
+// newOutputPathData collects the template values for a module under root
+func newOutputPathData(root string, path string, moduleName string, format string) OutputPathData {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+
+	ext, ok := FormatExtensions[format]
+	if !ok {
+		ext = format
+	}
+
+	return OutputPathData{
+		ModuleDir:  path,
+		ModuleName: moduleName,
+		DirName:    filepath.Base(path),
+		RelDir:     filepath.ToSlash(rel),
+		Format:     format,
+		Ext:        ext,
+	}
+}
@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+)
+
+func TestResolveOutputPath(t *testing.T) {
+	root := filepath.Join("infra")
+	module := filepath.Join("infra", "modules", "vpc")
+
+	tests := []struct {
+		name     string
+		template string
+		format   string
+		expected string
+	}{
+		{"Default markdown", DefaultOutputTemplate, "markdown", filepath.Join("infra", "modules", "vpc", "README.md")},
+		{"Default json", DefaultOutputTemplate, "json", filepath.Join("infra", "modules", "vpc", "README.json")},
+		{"Module name", "docs/{{.ModuleName}}.json", "json", filepath.Join("docs", "vpc.json")},
+		{"Relative directory", "docs/{{.RelDir}}/README.{{.Ext}}", "markdown", filepath.Join("docs", "modules", "vpc", "README.md")},
+		{"Plain path", "README.md", "markdown", "README.md"},
+		{"Template functions", "docs/{{.DirName | upper}}.{{.Ext}}", "markdown", filepath.Join("docs", "VPC.md")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := newOutputPathData(root, module, "vpc", test.format)
+			got, err := ResolveOutputPath(test.template, data)
+			if err != nil {
+				t.Fatalf("ResolveOutputPath failed: %v", err)
+			}
+			if got != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+
+	if _, err := ResolveOutputPath("{{.Unknown}}", newOutputPathData(root, module, "vpc", "json")); err == nil {
+		t.Errorf("Expected an error for an unknown template field")
+	}
+}
+
+func TestProcessModulesRejectsSharedOutputPath(t *testing.T) {
+	modules := []string{filepath.Join("infra", "a"), filepath.Join("infra", "b")}
+
+	err := ProcessModules("infra", modules, "markdown", "README.md", "example", "path/to/module", true, nil)
+	if err == nil || !strings.Contains(err.Error(), "would both be written to") {
+		t.Errorf("Expected an output path collision error, got %v", err)
+	}
+}
+
+func TestGenerateRejectsOutputPathSpellings(t *testing.T) {
+	root, err := ioutil.TempDir("", "tfdocs-output-spellings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// Each module names the same file in its own configuration
+	outputs := map[string]string{
+		"a": filepath.Join(root, "docs", "README.md"),
+		"b": root + "/./docs//README.md",
+	}
+	for name, output := range outputs {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "x" {}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, config.FileName), []byte("output: '"+output+"'\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = Generate(context.Background(), Options{Path: root, Recursive: true})
+	if err == nil || !strings.Contains(err.Error(), "would both be written to") {
+		t.Errorf("Expected an output path collision error, got %v", err)
+	}
+}
@@ -1,124 +1,149 @@
 package processor
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"sort"
 
+	"github.com/jishnusygal/terraform-docs-extended/pkg/cache"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
 	"github.com/jishnusygal/terraform-docs-extended/pkg/formatter"
 	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
 )
 
-// ProcessRecursively handles recursive directory traversal
-func ProcessRecursively(root string, format string, outputFile string, moduleName string, moduleSource string, quiet bool) error {
-	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip .git, .terraform directories
-		if info.IsDir() && (info.Name() == ".git" || info.Name() == ".terraform") {
-			return filepath.SkipDir
-		}
+// ProcessRecursively handles recursive directory traversal, processing the modules selected by opts.
+// When c is non-nil, modules whose inputs are unchanged are served from the cache.
+func ProcessRecursively(root string, opts DiscoveryOptions, format string, outputFile string, moduleName string, moduleSource string, quiet bool, c *cache.Cache) error {
+	options := legacyOptions(root, format, outputFile, moduleName, moduleSource, quiet, c)
+	options.Recursive = true
+	options.Discovery = opts
 
-		// Skip non-directories
-		if !info.IsDir() {
-			return nil
-		}
+	return firstError(Generate(context.Background(), options))
+}
 
-		// Check if this directory contains .tf files (potential module)
-		files, err := filepath.Glob(filepath.Join(path, "*.tf"))
-		if err != nil {
-			return err
-		}
+// ProcessModules processes the given module directories found under root.
+// Each module is written to the path produced by the outputTemplate, or by
+// DefaultOutputTemplate when it is empty.
+func ProcessModules(root string, modules []string, format string, outputTemplate string, moduleName string, moduleSource string, quiet bool, c *cache.Cache) error {
+	options := legacyOptions(root, format, outputTemplate, moduleName, moduleSource, quiet, c)
+	options.Recursive = true
+	options.Modules = modules
 
-		if len(files) > 0 {
-			// Generate output filename based on directory if not specified
-			outputPath := outputFile
-			if outputFile == "" {
-				// Use stdout if not recursive
-				if path == root && !filepath.HasPrefix(path, root+string(os.PathSeparator)) {
-					outputPath = ""
-				} else {
-					// Create output filename based on directory name
-					outputPath = filepath.Join(path, fmt.Sprintf("README.%s", format))
-				}
-			}
-			
-			// Use directory name as module name if processing recursively
-			modName := moduleName
-			if path != root {
-				modName = filepath.Base(path)
-			}
-			
-			// Process this directory as a module
-			if err := ProcessDirectory(path, format, outputPath, modName, moduleSource, quiet); err != nil {
-				return err
-			}
-		}
+	return firstError(Generate(context.Background(), options))
+}
 
-		return nil
-	})
+// ProcessDirectory handles a single directory.
+// The outputPath may be a template as accepted by ResolveOutputPath; when it is empty the
+// documentation is written to stdout.
+// When c is non-nil, the rendered output is reused if the module's inputs are unchanged.
+func ProcessDirectory(path string, format string, outputPath string, moduleName string, moduleSource string, quiet bool, c *cache.Cache) error {
+	return firstError(Generate(context.Background(), legacyOptions(path, format, outputPath, moduleName, moduleSource, quiet, c)))
 }
 
-// ProcessDirectory handles a single directory
-func ProcessDirectory(path string, format string, outputPath string, moduleName string, moduleSource string, quiet bool) error {
-	if !quiet {
-		fmt.Printf("Processing module: %s\n", path)
-	}
-	
-	// Extract module information
-	module, err := ExtractModuleInfo(path, moduleName)
+// firstError returns err, or the first error diagnostic of result if err is nil
+func firstError(result Result, err error) error {
 	if err != nil {
-		return fmt.Errorf("failed to extract module info: %v", err)
+		return err
 	}
-
-	// Generate the documentation with our extended usage section
-	docContent := formatter.GenerateDoc(module, format, moduleSource)
-
-	// Output the documentation
-	if outputPath != "" {
-		if err := os.WriteFile(outputPath, []byte(docContent), 0644); err != nil {
-			return fmt.Errorf("failed to write output file: %v", err)
-		}
-		if !quiet {
-			fmt.Printf("Documentation written to: %s\n", outputPath)
+	for _, d := range result.Diagnostics {
+		if d.Severity == diag.Error {
+			return fmt.Errorf("%s: %s", d.Location(), d.Message)
 		}
-	} else {
-		fmt.Println(docContent)
 	}
-	
 	return nil
 }
 
-// ExtractModuleInfo collects information about a Terraform module
-func ExtractModuleInfo(path string, moduleName string) (formatter.Module, error) {
-	// Run terraform-docs to get base information
-	tfDocsVars, err := terraform.ExtractTerraformDocsInfo(path)
-	if err != nil {
-		log.Printf("Warning: Failed to extract info from terraform-docs: %v", err)
+// legacyOptions builds Options for the positional Process* functions, which
+// write documentation to stdout and progress messages to stderr
+func legacyOptions(path string, format string, output string, moduleName string, moduleSource string, quiet bool, c *cache.Cache) Options {
+	options := Options{
+		Path:         path,
+		Format:       format,
+		Output:       output,
+		ModuleName:   moduleName,
+		ModuleSource: moduleSource,
+		Cache:        c,
+		Stdout:       os.Stdout,
+	}
+	if !quiet {
+		options.Log = os.Stderr
+	}
+	return options
+}
+
+// ExtractModuleInfo collects information about a Terraform module.
+// Problems that still allow partial documentation are returned as warning diagnostics;
+// a terraform-docs run that times out or is interrupted is returned as an error.
+func ExtractModuleInfo(ctx context.Context, path string, moduleName string) (formatter.Module, diag.Diagnostics, error) {
+	var diags diag.Diagnostics
+
+	// Run terraform-docs once to get base information shared by all formatters
+	tfDocsVars := make(map[string]terraform.Variable)
+	docs, err := terraform.LoadDocs(ctx, path)
+	if err != nil && ctx.Err() != nil {
+		// A timed out or interrupted run must not fall back to reduced content
+		return formatter.Module{}, diags, err
+	} else if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Module:   path,
+			Message:  fmt.Sprintf("Failed to extract info from terraform-docs: %v", err),
+		})
 		// Continue with empty variables map
-		tfDocsVars = make(map[string]terraform.Variable)
+	} else {
+		tfDocsVars = docs.Variables()
 	}
 
 	// Parse Terraform files directly for better type extraction
 	parsedVars, err := terraform.ParseModuleFiles(path)
 	if err != nil {
-		log.Printf("Warning: Failed to parse module files directly: %v", err)
+		d := diag.Diagnostic{
+			Severity: diag.Warning,
+			Module:   path,
+			Message:  fmt.Sprintf("Failed to parse module files directly: %v", err),
+		}
+		var fileErr *terraform.FileError
+		if errors.As(err, &fileErr) {
+			d.File, d.Line = fileErr.File, fileErr.Line
+			d.Message = fmt.Sprintf("Failed to parse module file: %v", fileErr.Err)
+		}
+		diags = append(diags, d)
 		// Continue with terraform-docs variables only
 	}
 
 	// Convert terraform.Variable to formatter.Variable
+	merged := MergeVariables(tfDocsVars, parsedVars)
 	formatterVars := make(map[string]formatter.Variable)
-	for name, v := range MergeVariables(tfDocsVars, parsedVars) {
+	for name, v := range merged {
 		formatterVars[name] = formatter.Variable{
 			Name:        v.Name,
 			Type:        v.Type,
 			Description: v.Description,
 			Default:     v.Default,
 			Required:    v.Required,
+			Sensitive:   v.Sensitive,
+			TypeExpr:    v.TypeExpr,
+			Enum:        v.Enum,
+			Group:       firstNonEmpty(v.Group, terraform.DescriptionGroup(v.Description)),
+		}
+	}
+
+	// A type that cannot be parsed leaves the input unconstrained in the JSON Schema
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := terraform.ParseType(merged[name].TypeExpr); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Module:   path,
+				Message:  fmt.Sprintf("Failed to parse the type of input %q, its values are not validated: %v", name, err),
+			})
 		}
 	}
 
@@ -129,7 +154,33 @@ func ExtractModuleInfo(path string, moduleName string) (formatter.Module, error)
 		Variables: formatterVars,
 	}
 
-	return module, nil
+	// Attach the content terraform-docs rendered for this module
+	if docs != nil {
+		module.Header = docs.Header
+		module.Footer = docs.Footer
+		module.Markdown = docs.Markdown
+		module.Outputs = docs.Section("outputs")
+		module.Resources = docs.Section("resources")
+		module.Providers = docs.Section("providers")
+		module.Requirements = docs.Section("requirements")
+		module.Modules = docs.Section("modules")
+	} else if docsCfg, err := terraform.LoadModuleDocsConfig(path); err == nil {
+		// The header and footer do not need terraform-docs; a broken
+		// configuration was already reported above
+		module.Header, _ = docsCfg.Header(path)
+		module.Footer, _ = docsCfg.Footer(path)
+	}
+
+	// Parse the module calls and resources for the architecture diagram
+	if module.Composition, err = terraform.ParseComposition(path); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Module:   path,
+			Message:  fmt.Sprintf("Failed to parse the module composition: %v", err),
+		})
+	}
+
+	return module, diags, nil
 }
 
 // IsTerraformDocsInstalled checks if terraform-docs is available
@@ -157,6 +208,12 @@ func MergeVariables(tfDocsVars map[string]terraform.Variable, parsedVars map[str
 			if v.Type != "" {
 				existing.Type = v.Type
 			}
+			existing.Sensitive = existing.Sensitive || v.Sensitive
+			if v.TypeExpr != "" {
+				existing.TypeExpr = v.TypeExpr
+			}
+			existing.Enum = v.Enum
+			existing.Group = v.Group
 			result[name] = existing
 		} else {
 			// Add any variables we found that terraform-docs didn't
@@ -0,0 +1,318 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/config"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/formatter"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/source"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// moduleSettings holds the effective settings used to document one module
+type moduleSettings struct {
+	Format     string
+	OutputPath string
+	Name       string
+	Source     string
+	Version    string
+	Doc        formatter.Options
+
+	// InjectTemplate, when set, injects the documentation into the existing
+	// output file using this terraform-docs output template
+	InjectTemplate string
+}
+
+// cacheParams returns the settings that affect the rendered output, for use in a cache key
+func (s moduleSettings) cacheParams() []string {
+	params := []string{
+		s.Format,
+		s.Name,
+		s.Source,
+		s.Version,
+		strings.Join(s.Doc.Sections, ","),
+		fmt.Sprintf("show_defaults=%t", s.Doc.ShowDefaults),
+		"style=" + s.Doc.UsageStyle,
+		"sort=" + s.Doc.SortBy,
+		fmt.Sprintf("omit_required=%t,omit_default=%t,omit_optional=%t", s.Doc.OmitRequired, s.Doc.OmitDefault, s.Doc.OmitOptionalInputs),
+		fmt.Sprintf("toc=%t,anchors=%t", s.Doc.TOC, s.Doc.Anchors),
+	}
+
+	for _, group := range s.Doc.InputGroups {
+		params = append(params, fmt.Sprintf("group=%q %q", group.Name, group.Inputs))
+	}
+
+	params = append(params, sortedValues(s.Doc.ExampleValues)...)
+
+	// Embedded example code changes independently of the module
+	for _, example := range s.Doc.Examples {
+		params = append(params, fmt.Sprintf("example=%q %q %q %v %q", example.Name, example.Kind, example.Description, sortedValues(example.Values), example.Code))
+	}
+
+	return params
+}
+
+// sortedValues formats a map of example values in a stable order
+func sortedValues(values map[string]string) []string {
+	pairs := make([]string, 0, len(values))
+	for name, value := range values {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// moduleConfig returns the project configuration merged with the
+// configuration file in the module directory, if there is one, and then with
+// the overrides, which take precedence over both
+func (g *generator) moduleConfig(path string) (config.Config, error) {
+	cfg := g.opts.Config
+	file := filepath.Join(path, config.FileName)
+	if _, err := os.Stat(file); err == nil {
+		override, err := config.LoadFile(file)
+		if err != nil {
+			return cfg, err
+		}
+		cfg = cfg.Merge(override)
+	}
+	return cfg.Merge(g.opts.Overrides), nil
+}
+
+// moduleSettings combines the options, the configuration and the built-in
+// defaults into the settings for the module at path. Options set explicitly
+// take precedence over the configuration.
+func (g *generator) moduleSettings(ctx context.Context, path string) (moduleSettings, error) {
+	var s moduleSettings
+
+	cfg, err := g.moduleConfig(path)
+	if err != nil {
+		return s, err
+	}
+	docsCfg, err := terraform.LoadModuleDocsConfig(path)
+	if err != nil {
+		return s, err
+	}
+	for _, key := range docsCfg.Unsupported {
+		g.report(diag.Diagnostic{
+			Severity: diag.Warning,
+			Module:   path,
+			File:     docsCfg.File,
+			Line:     key.Line,
+			Message:  fmt.Sprintf("Setting %q of the terraform-docs configuration is not supported and is ignored", key.Name),
+		})
+	}
+
+	s.Format = firstNonEmpty(g.opts.Format, cfg.Format, "markdown")
+	if !formatter.IsSupportedFormat(s.Format) {
+		return s, fmt.Errorf("unsupported output format for %s: %s", path, s.Format)
+	}
+
+	root := g.opts.Path
+	if !g.opts.Recursive {
+		root = path
+	}
+
+	// Resolve the module name; in recursive mode each module is named after its directory
+	data := newOutputPathData(root, path, source.InferName(path), s.Format)
+	nameTemplate := cfg.Usage.Name
+	if g.opts.Recursive && path != g.opts.Path {
+		nameTemplate = firstNonEmpty(nameTemplate, "{{.ModuleName}}")
+	} else {
+		nameTemplate = firstNonEmpty(g.opts.ModuleName, nameTemplate, "example")
+	}
+	if s.Name, err = expandTemplate("usage name", nameTemplate, data); err != nil {
+		return s, err
+	}
+	if !source.IsValidIdentifier(s.Name) {
+		return s, fmt.Errorf("invalid module name %q for %s: must start with a letter or underscore and contain only letters, digits, underscores and hyphens", s.Name, path)
+	}
+	data.ModuleName = s.Name
+
+	sourceTemplate := firstNonEmpty(g.opts.ModuleSource, cfg.Usage.Source)
+	if s.Source, err = expandTemplate("usage source", sourceTemplate, data); err != nil {
+		return s, err
+	}
+	s.Version = cfg.Usage.Version
+	if s.Source == "" {
+		if s.Source, s.Version, err = g.inferSource(ctx, path, cfg); err != nil {
+			return s, err
+		}
+	}
+	if s.Source == "" {
+		s.Source = formatter.DefaultModuleSource
+	}
+
+	outputTemplate := firstNonEmpty(g.opts.Output, cfg.Output)
+	if outputTemplate == "" && g.opts.Recursive && s.Format == "markdown" && docsCfg.Output.File != "" {
+		// In recursive mode, fall back to the output file of the terraform-docs
+		// configuration; a single module is still written to Stdout
+		outputTemplate = filepath.Join(path, docsCfg.Output.File)
+		if docsCfg.Output.Mode != terraform.OutputModeReplace {
+			s.InjectTemplate = firstNonEmpty(docsCfg.Output.Template, terraform.DefaultOutputTemplate)
+		}
+	}
+	if outputTemplate == "" && g.opts.Recursive {
+		outputTemplate = DefaultOutputTemplate
+	}
+	if s.OutputPath, err = ResolveOutputPath(outputTemplate, data); err != nil {
+		return s, err
+	}
+
+	examples, err := moduleExamples(path, cfg.Usage, s.Source, s.Version)
+	if err != nil {
+		return s, err
+	}
+	for _, section := range cfg.Sections {
+		if !formatter.IsSection(section) {
+			return s, fmt.Errorf("unknown section for %s: %s. Must be one of: %s", path, section, strings.Join(formatter.SectionNames, ", "))
+		}
+	}
+	groups, err := inputGroups(path, cfg.InputGroups)
+	if err != nil {
+		return s, err
+	}
+	if style := cfg.Usage.Style; style != "" && !formatter.IsUsageStyle(style) {
+		return s, fmt.Errorf("unsupported usage style for %s: %s. Must be one of: %s", path, style, strings.Join(formatter.UsageStyles, ", "))
+	}
+
+	s.Doc = formatter.Options{
+		Sections:      selectSections(cfg.Sections, docsCfg),
+		ShowDefaults:  config.Bool(cfg.Usage.ShowDefaults, false),
+		ExampleValues: cfg.Usage.ExampleValues,
+		ModuleVersion: s.Version,
+		Examples:      examples,
+		UsageStyle:    cfg.Usage.Style,
+		InputGroups:   groups,
+		SortBy:        docsCfg.SortBy(),
+		OmitRequired:  !docsCfg.ShowRequired(),
+		OmitDefault:   !docsCfg.ShowDefault(),
+
+		OmitOptionalInputs: !config.Bool(cfg.Tfvars.Optional, true),
+
+		TOC:     config.Bool(cfg.Markdown.TOC, false),
+		Anchors: config.Bool(cfg.Markdown.Anchors, false),
+	}
+	if s.Doc.Anchors && s.Format == "markdown" && !docsCfg.RowAnchors() {
+		g.report(diag.Diagnostic{
+			Severity: diag.Warning,
+			Module:   path,
+			File:     docsCfg.File,
+			Message:  "The usage examples link to input anchors, which settings.anchor or settings.html of the terraform-docs configuration turn off",
+		})
+	}
+
+	return s, nil
+}
+
+// inputGroups converts the configured input groups, checking that every
+// group has a name and valid patterns
+func inputGroups(path string, configured []config.InputGroup) ([]formatter.InputGroup, error) {
+	groups := make([]formatter.InputGroup, 0, len(configured))
+	for _, group := range configured {
+		if strings.TrimSpace(group.Name) == "" {
+			return nil, fmt.Errorf("input groups for %s must have a name", path)
+		}
+		for _, pattern := range group.Inputs {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid input pattern for group %s of %s: %s", group.Name, path, pattern)
+			}
+		}
+		groups = append(groups, formatter.InputGroup{Name: group.Name, Inputs: group.Inputs})
+	}
+	return groups, nil
+}
+
+// inferSource infers the source and version of a module as configured by
+// usage.infer_source. A configured version takes precedence over an inferred one.
+func (g *generator) inferSource(ctx context.Context, path string, cfg config.Config) (string, string, error) {
+	// Paths are relative to the root in recursive mode and to the parent directory otherwise
+	root := g.opts.Path
+	if !g.opts.Recursive {
+		root = filepath.Dir(path)
+	}
+
+	src, err := g.sources.Resolve(ctx, path, source.Options{
+		Kind:     cfg.Usage.InferSource,
+		Registry: cfg.Usage.Registry,
+		Provider: cfg.Usage.Provider,
+		Root:     root,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to infer the source of %s: %v", path, err)
+	}
+
+	return src.Address, firstNonEmpty(cfg.Usage.Version, src.Version), nil
+}
+
+// ownSections are the sections terraform-docs does not render, so its
+// configuration cannot hide them
+var ownSections = map[string]bool{
+	formatter.SectionTOC:          true,
+	formatter.SectionArchitecture: true,
+	formatter.SectionUsage:        true,
+	formatter.SectionExamples:     true,
+}
+
+// selectSections narrows the sections chosen in our configuration, or all
+// sections, to those shown by the terraform-docs configuration, keeping their order
+func selectSections(sections []string, docsCfg *terraform.DocsConfig) []string {
+	if len(docsCfg.Sections.Show) == 0 && len(docsCfg.Sections.Hide) == 0 {
+		return sections
+	}
+	if len(sections) == 0 {
+		sections = formatter.AllSections
+	}
+
+	selected := []string{}
+	for _, section := range sections {
+		if ownSections[strings.ToLower(section)] || docsCfg.ShowSection(section) {
+			selected = append(selected, section)
+		}
+	}
+	return selected
+}
+
+// resolveSettings computes the settings of every module up front so that
+// configuration errors surface before anything is written and modules never
+// overwrite each other
+func (g *generator) resolveSettings(ctx context.Context, modules []string) ([]moduleSettings, error) {
+	settings := make([]moduleSettings, len(modules))
+	writers := make(map[string]string)
+	for i, path := range modules {
+		s, err := g.moduleSettings(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.OutputPath != "" {
+			// Spellings such as docs/README.md and ./docs//README.md name the same file
+			file, err := filepath.Abs(s.OutputPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output path %s: %v", s.OutputPath, err)
+			}
+			if other, ok := writers[file]; ok {
+				return nil, fmt.Errorf("modules %s and %s would both be written to %s; use a template such as %q in --out", other, path, s.OutputPath, DefaultOutputTemplate)
+			}
+			writers[file] = path
+		}
+		settings[i] = s
+	}
+
+	return settings, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// DefaultExamplesDir is the directory searched for examples when the
+// configuration does not name one
+const DefaultExamplesDir = "examples"
+
+// ValidateExamples checks the examples of the modules selected by opts against
+// the modules' inputs. Every module block in the examples directory whose source
+// points at the module is checked for arguments the module does not declare,
+// required inputs that are not set and literal values of an incompatible type.
+// Problems are reported as error diagnostics.
+func ValidateExamples(ctx context.Context, opts Options) (diag.Diagnostics, error) {
+	g := newGenerator(opts)
+
+	modules, err := SelectModules(ctx, g.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := 0
+	for _, path := range modules {
+		if err := ctx.Err(); err != nil {
+			return g.result.Diagnostics, err
+		}
+
+		calls, err := g.exampleCalls(ctx, path)
+		if err != nil {
+			g.report(diag.Diagnostic{Severity: diag.Error, Module: path, Message: err.Error()})
+			continue
+		}
+		if len(calls) == 0 {
+			continue
+		}
+
+		variables, err := terraform.ParseModuleFiles(path)
+		if err != nil {
+			g.report(diag.Diagnostic{Severity: diag.Error, Module: path, Message: fmt.Sprintf("Failed to parse module files: %v", err)})
+			continue
+		}
+
+		for _, call := range calls {
+			for _, problem := range terraform.CheckModuleCall(call, variables) {
+				g.report(diag.Diagnostic{
+					Severity: diag.Error,
+					Module:   path,
+					File:     problem.File,
+					Line:     problem.Line,
+					Message:  problem.Err.Error(),
+				})
+			}
+		}
+		checked += len(calls)
+	}
+
+	g.infof("%d example module call(s) checked", checked)
+	return g.result.Diagnostics, nil
+}
+
+// exampleCalls returns the module blocks in the examples of the module at path
+// that call the module
+func (g *generator) exampleCalls(ctx context.Context, path string) ([]terraform.ModuleCall, error) {
+	cfg, err := g.moduleConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(path, firstNonEmpty(cfg.Usage.ExamplesDir, DefaultExamplesDir))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+
+	exampleDirs, err := FindModules(ctx, dir, DiscoveryOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	target := canonicalDir(path)
+	var calls []terraform.ModuleCall
+	for _, exampleDir := range exampleDirs {
+		dirCalls, err := terraform.ParseModuleCalls(exampleDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, call := range dirCalls {
+			if terraform.IsLocalSource(call.Source) && canonicalDir(filepath.Join(exampleDir, call.Source)) == target {
+				calls = append(calls, call)
+			}
+		}
+	}
+
+	return calls, nil
+}
@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/diag"
+)
+
+func TestValidateExamples(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.tf": `variable "name" {
+  type = string
+}
+
+variable "size" {
+  type    = number
+  default = 1
+}
+`,
+		"examples/basic/main.tf": `module "app" {
+  source = "../.."
+  name   = "basic"
+}
+`,
+		"examples/broken/main.tf": `module "app" {
+  source = "../../"
+  size   = "large"
+  colour = "red"
+}
+
+module "other" {
+  source = "terraform-aws-modules/vpc/aws"
+  colour = "red"
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diags, err := ValidateExamples(context.Background(), Options{Path: dir})
+	if err != nil {
+		t.Fatalf("ValidateExamples failed: %v", err)
+	}
+
+	errors := diags.Filter(diag.Error)
+	expected := []string{
+		`sets "colour", which the module does not declare`,
+		`sets "size" to a string, which is not compatible with type number`,
+		`does not set the required input "name"`,
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %+v", len(expected), errors)
+	}
+	for i, message := range expected {
+		if !strings.Contains(errors[i].Message, message) {
+			t.Errorf("Expected error %d to contain %q, got %q", i, message, errors[i].Message)
+		}
+		if filepath.Base(filepath.Dir(errors[i].File)) != "broken" {
+			t.Errorf("Expected error %d to refer to the broken example, got %s", i, errors[i].File)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Output statuses reported in ModuleResult.Status
+const (
+	StatusStdout    = "stdout"
+	StatusUpdated   = "updated"
+	StatusUnchanged = "unchanged"
+)
+
+// injectContent places content into existing the way terraform-docs' inject
+// mode does: outputTemplate wraps content between a begin and an end marker,
+// given by its first and last lines, and replaces the text between those
+// markers in existing. When existing has no markers the block is appended.
+func injectContent(existing string, content string, outputTemplate string) (string, error) {
+	tmpl, err := template.New("output").Parse(outputTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid output template %q: %v", outputTemplate, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, struct{ Content string }{strings.TrimSpace(content)}); err != nil {
+		return "", fmt.Errorf("failed to expand output template %q: %v", outputTemplate, err)
+	}
+	block := sb.String()
+
+	lines := strings.Split(strings.TrimSpace(outputTemplate), "\n")
+	begin, end := lines[0], lines[len(lines)-1]
+	if len(lines) < 2 || strings.Contains(begin, "{{") || strings.Contains(end, "{{") {
+		return "", fmt.Errorf("output template %q must start and end with a marker line", outputTemplate)
+	}
+
+	start := strings.Index(existing, begin)
+	stop := strings.Index(existing, end)
+	if start >= 0 && stop > start {
+		return existing[:start] + block + existing[stop+len(end):], nil
+	}
+
+	if strings.TrimSpace(existing) == "" {
+		return block + "\n", nil
+	}
+	return strings.TrimRight(existing, "\n") + "\n\n" + block + "\n", nil
+}
+
+// writeFileAtomic replaces the file at path with content by writing a
+// temporary file next to it and renaming it into place, so readers never see
+// a partially written file. The existing file mode is preserved, and the file
+// is left untouched when its content already matches. It reports whether the
+// file was written.
+func writeFileAtomic(path string, content []byte) (bool, error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return false, fmt.Errorf("%s is a directory", path)
+		}
+		mode = info.Mode().Perm()
+
+		existing, err := ioutil.ReadFile(path)
+		if err == nil && bytes.Equal(existing, content) {
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return false, err
+	}
+	// Remove the temporary file unless it was renamed into place
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+	renamed = true
+
+	return true, nil
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "docs", "README.md")
+
+	// A new file is created along with its directory
+	written, err := writeFileAtomic(path, []byte("first"))
+	if err != nil || !written {
+		t.Fatalf("Expected new file to be written, got written=%v err=%v", written, err)
+	}
+
+	// Identical content leaves the file untouched
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatal(err)
+	}
+	written, err = writeFileAtomic(path, []byte("first"))
+	if err != nil || written {
+		t.Fatalf("Expected unchanged file to be skipped, got written=%v err=%v", written, err)
+	}
+	if info, _ := os.Stat(path); !info.ModTime().Equal(past) {
+		t.Errorf("Expected modification time to be preserved, got %v", info.ModTime())
+	}
+
+	// Changed content replaces the file and keeps its mode
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	written, err = writeFileAtomic(path, []byte("second"))
+	if err != nil || !written {
+		t.Fatalf("Expected changed file to be written, got written=%v err=%v", written, err)
+	}
+	if content, _ := ioutil.ReadFile(path); string(content) != "second" {
+		t.Errorf("Expected new content, got %q", content)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected file mode 0600 to be preserved, got %v", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind
+	entries, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only README.md in the output directory, got %d entries", len(entries))
+	}
+}
+
+func TestInjectContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{"Empty file", "", "<!-- BEGIN_TF_DOCS -->\nnew\n<!-- END_TF_DOCS -->\n"},
+		{"Existing markers", "# Title\n\n<!-- BEGIN_TF_DOCS -->\nold\n<!-- END_TF_DOCS -->\n\nMore\n", "# Title\n\n<!-- BEGIN_TF_DOCS -->\nnew\n<!-- END_TF_DOCS -->\n\nMore\n"},
+		{"No markers", "# Title\n", "# Title\n\n<!-- BEGIN_TF_DOCS -->\nnew\n<!-- END_TF_DOCS -->\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := injectContent(test.existing, "new\n", terraform.DefaultOutputTemplate)
+			if err != nil {
+				t.Fatalf("injectContent failed: %v", err)
+			}
+			if got != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+
+	if _, err := injectContent("", "new", "{{ .Content }}"); err == nil {
+		t.Errorf("Expected an error for a template without markers")
+	}
+}
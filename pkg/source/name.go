@@ -0,0 +1,59 @@
+package source
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// genericDirNames lists directory names that say nothing about the module
+// they contain, so its name is taken from the parent directory instead
+var genericDirNames = map[string]bool{
+	"module":    true,
+	"modules":   true,
+	"src":       true,
+	"terraform": true,
+	"tf":        true,
+}
+
+var (
+	identifierRegex   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	invalidCharsRegex = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// InferName derives an idiomatic module block label from a module directory:
+// "terraform-aws-vpc" becomes "vpc" and "network-core" becomes "network_core".
+// Generic directory names such as "modules" are skipped in favour of their parent.
+func InferName(modulePath string) string {
+	dir := absPath(modulePath)
+	name := filepath.Base(dir)
+	for genericDirNames[strings.ToLower(name)] {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		name = filepath.Base(dir)
+	}
+
+	name, _ = SplitRepositoryName(name)
+	return Identifier(name)
+}
+
+// Identifier converts s into a valid HCL identifier, replacing hyphens and
+// other characters that are not letters, digits or underscores with an underscore
+func Identifier(s string) string {
+	id := strings.Trim(invalidCharsRegex.ReplaceAllString(s, "_"), "_")
+	if id == "" {
+		return "this"
+	}
+	if id[0] >= '0' && id[0] <= '9' {
+		id = "_" + id
+	}
+	return id
+}
+
+// IsValidIdentifier reports whether name can be used as a module block label
+func IsValidIdentifier(name string) bool {
+	return identifierRegex.MatchString(name)
+}
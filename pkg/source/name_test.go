@@ -0,0 +1,39 @@
+package source
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestInferName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{filepath.Join("repos", "terraform-aws-vpc"), "vpc"},
+		{filepath.Join("repos", "terraform-google-network-core"), "network_core"},
+		{filepath.Join("infra", "network-core"), "network_core"},
+		{filepath.Join("infra", "3tier.app"), "_3tier_app"},
+		{filepath.Join("infra", "terraform-aws-vpc", "modules"), "vpc"},
+		{filepath.Join("infra", "dns", "terraform"), "dns"},
+	}
+
+	for _, test := range tests {
+		if got := InferName(test.path); got != test.expected {
+			t.Errorf("InferName(%q) = %q, expected %q", test.path, got, test.expected)
+		}
+	}
+}
+
+func TestIsValidIdentifier(t *testing.T) {
+	for _, name := range []string{"vpc", "network_core", "my-module", "_internal"} {
+		if !IsValidIdentifier(name) {
+			t.Errorf("Expected %q to be a valid identifier", name)
+		}
+	}
+	for _, name := range []string{"", "3tier", "my module", "a.b", "modules/vpc"} {
+		if IsValidIdentifier(name) {
+			t.Errorf("Expected %q to be an invalid identifier", name)
+		}
+	}
+}
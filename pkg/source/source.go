@@ -0,0 +1,302 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jishnusygal/terraform-docs-extended/pkg/git"
+	"github.com/jishnusygal/terraform-docs-extended/pkg/terraform"
+)
+
+// Ways of inferring a module source accepted in Options.Kind
+const (
+	KindNone     = "none"
+	KindAuto     = "auto"
+	KindGit      = "git"
+	KindRegistry = "registry"
+	KindPath     = "path"
+)
+
+// Kinds lists the accepted values of Options.Kind
+var Kinds = []string{KindNone, KindAuto, KindGit, KindRegistry, KindPath}
+
+// Options controls how a module source is inferred
+type Options struct {
+	// Kind selects the kind of source; KindAuto uses a registry source when
+	// Registry is set, a git source inside a repository with a remote, and a
+	// relative path otherwise
+	Kind string
+	// Registry is the registry namespace, e.g. "app.terraform.io/org"
+	Registry string
+	// Provider is the registry provider; it is inferred from the module when empty
+	Provider string
+	// Remote is the git remote to use; it defaults to "origin"
+	Remote string
+	// Root is the directory relative paths start from
+	Root string
+}
+
+// Source is an inferred module source
+type Source struct {
+	// Address is the value of the source argument
+	Address string
+	// Version is the value of the version argument; only registry sources have one
+	Version string
+}
+
+// IsValidKind reports whether kind is an accepted value of Options.Kind
+func IsValidKind(kind string) bool {
+	for _, valid := range Kinds {
+		if kind == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolver infers module sources. It remembers what it learns about each git
+// repository, so one Resolver should be used for all modules of a run.
+type Resolver struct {
+	mu    sync.Mutex
+	repos map[string]*repository
+}
+
+// repository holds what is known about a git repository
+type repository struct {
+	top string
+	url string
+	tag string
+}
+
+// NewResolver creates a Resolver
+func NewResolver() *Resolver {
+	return &Resolver{repos: make(map[string]*repository)}
+}
+
+// Resolve infers the source of the module at modulePath. It returns an empty
+// Source for KindNone.
+func (r *Resolver) Resolve(ctx context.Context, modulePath string, opts Options) (Source, error) {
+	switch opts.Kind {
+	case "", KindNone:
+		return Source{}, nil
+	case KindPath:
+		return pathSource(modulePath, opts.Root)
+	case KindGit:
+		return r.gitSource(ctx, modulePath, opts)
+	case KindRegistry:
+		return r.registrySource(ctx, modulePath, opts)
+	case KindAuto:
+		if opts.Registry != "" {
+			return r.registrySource(ctx, modulePath, opts)
+		}
+		if src, err := r.gitSource(ctx, modulePath, opts); err == nil {
+			return src, nil
+		} else if ctx.Err() != nil {
+			return Source{}, err
+		}
+		return pathSource(modulePath, opts.Root)
+	}
+	return Source{}, fmt.Errorf("unknown source kind: %s. Must be one of: %s", opts.Kind, strings.Join(Kinds, ", "))
+}
+
+// pathSource returns the path of the module relative to root
+func pathSource(modulePath string, root string) (Source, error) {
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return Source{}, err
+	}
+	absModule, err := filepath.Abs(modulePath)
+	if err != nil {
+		return Source{}, err
+	}
+
+	rel, err := filepath.Rel(absRoot, absModule)
+	if err != nil {
+		return Source{}, err
+	}
+	rel = filepath.ToSlash(rel)
+
+	// Terraform only treats sources starting with ./ or ../ as local paths
+	if rel == "." {
+		return Source{Address: "./"}, nil
+	}
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return Source{Address: rel}, nil
+}
+
+// gitSource returns the address of the module in its repository's remote,
+// pinned to the latest tag when there is one
+func (r *Resolver) gitSource(ctx context.Context, modulePath string, opts Options) (Source, error) {
+	repo, err := r.repository(ctx, modulePath, opts.Remote)
+	if err != nil {
+		return Source{}, err
+	}
+
+	address, err := GitAddress(repo.url)
+	if err != nil {
+		return Source{}, err
+	}
+
+	subdir, err := repoSubdir(repo.top, modulePath)
+	if err != nil {
+		return Source{}, err
+	}
+	if subdir != "" {
+		address += "//" + subdir
+	}
+	if repo.tag != "" {
+		address += "?ref=" + repo.tag
+	}
+
+	return Source{Address: address}, nil
+}
+
+// registrySource returns the registry address of the module, with the latest
+// tag as its version when the module is in a git repository
+func (r *Resolver) registrySource(ctx context.Context, modulePath string, opts Options) (Source, error) {
+	if opts.Registry == "" {
+		return Source{}, fmt.Errorf("a registry namespace is required for registry sources")
+	}
+
+	name, provider := SplitRepositoryName(filepath.Base(absPath(modulePath)))
+	if opts.Provider != "" {
+		provider = opts.Provider
+	}
+	if provider == "" {
+		providers, err := terraform.ModuleProviders(modulePath)
+		if err != nil {
+			return Source{}, err
+		}
+		if len(providers) == 0 {
+			return Source{}, fmt.Errorf("cannot infer the registry provider of %s; set it in the configuration", modulePath)
+		}
+		provider = providers[0]
+	}
+
+	src := Source{
+		Address: strings.TrimRight(opts.Registry, "/") + "/" + name + "/" + provider,
+	}
+
+	// The version is optional; a module outside git simply has none
+	if repo, err := r.repository(ctx, modulePath, opts.Remote); err == nil {
+		src.Version = strings.TrimPrefix(repo.tag, "v")
+	} else if ctx.Err() != nil {
+		return Source{}, err
+	}
+
+	return src, nil
+}
+
+// repository looks up the repository containing modulePath
+func (r *Resolver) repository(ctx context.Context, modulePath string, remote string) (*repository, error) {
+	if remote == "" {
+		remote = "origin"
+	}
+
+	top, err := git.TopLevel(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	key := top + "\x00" + remote
+	r.mu.Lock()
+	repo, ok := r.repos[key]
+	r.mu.Unlock()
+	if ok {
+		return repo, nil
+	}
+
+	repo = &repository{top: top}
+	if repo.url, err = git.RemoteURL(ctx, top, remote); err != nil {
+		return nil, err
+	}
+	if repo.tag, err = git.LatestTag(ctx, top); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.repos[key] = repo
+	r.mu.Unlock()
+
+	return repo, nil
+}
+
+// repoSubdir returns the slash-separated path of modulePath within the
+// repository at top, or an empty string for the repository root
+func repoSubdir(top string, modulePath string) (string, error) {
+	// Resolve symlinks on both sides, since git reports the real path
+	realTop, err := filepath.EvalSymlinks(top)
+	if err != nil {
+		return "", err
+	}
+	realModule, err := filepath.EvalSymlinks(absPath(modulePath))
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(realTop, realModule)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+var scpLikeURL = regexp.MustCompile(`^([A-Za-z0-9._-]+@)?([A-Za-z0-9.-]+):([^/].*)$`)
+
+// GitAddress converts a git remote URL into a Terraform git source address.
+// Credentials in HTTPS URLs are removed, and scp-like SSH URLs such as
+// "git@github.com:org/repo.git" become "git::ssh://git@github.com/org/repo.git".
+func GitAddress(remoteURL string) (string, error) {
+	if match := scpLikeURL.FindStringSubmatch(remoteURL); match != nil && !strings.Contains(remoteURL, "://") {
+		return "git::ssh://" + match[1] + match[2] + "/" + match[3], nil
+	}
+
+	u, err := url.Parse(remoteURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("unsupported git remote URL: %s", remoteURL)
+	}
+
+	switch u.Scheme {
+	case "https", "http":
+		// Never publish tokens embedded in the remote URL
+		u.User = nil
+	case "ssh":
+	default:
+		return "", fmt.Errorf("unsupported git remote URL: %s", remoteURL)
+	}
+
+	return "git::" + u.String(), nil
+}
+
+var repositoryNameRegex = regexp.MustCompile(`^terraform-([a-z0-9]+)-(.+)$`)
+
+// SplitRepositoryName splits a registry-style repository name such as
+// "terraform-aws-vpc" into the module name and provider. Other names are
+// returned unchanged with an empty provider.
+func SplitRepositoryName(dirName string) (name string, provider string) {
+	if match := repositoryNameRegex.FindStringSubmatch(dirName); match != nil {
+		return match[2], match[1]
+	}
+	return dirName, ""
+}
+
+// absPath returns the absolute form of path, or path itself if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
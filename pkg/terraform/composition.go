@@ -0,0 +1,171 @@
+package terraform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Resource modes
+const (
+	ResourceManaged = "managed"
+	ResourceData    = "data"
+)
+
+// Resource represents a resource or data block
+type Resource struct {
+	// Mode is ResourceManaged or ResourceData
+	Mode     string
+	Type     string
+	Name     string
+	Provider string
+}
+
+// Address returns the address of the resource, such as "aws_s3_bucket.logs"
+// or "data.aws_iam_policy_document.read"
+func (r Resource) Address() string {
+	if r.Mode == ResourceData {
+		return "data." + r.Type + "." + r.Name
+	}
+	return r.Type + "." + r.Name
+}
+
+// Flow is a reference that carries a value from one module or resource to
+// another. From and To are addresses such as "module.vpc" or
+// "aws_instance.web".
+type Flow struct {
+	From string
+	To   string
+	// Label names the value, such as "vpc_id → vpc_id" for a module output
+	// passed as a module input
+	Label string
+}
+
+// Composition describes how a module is assembled from child modules and resources
+type Composition struct {
+	Modules   []ModuleCall
+	Resources []Resource
+	Flows     []Flow
+}
+
+// IsEmpty reports whether the module calls no modules and declares no resources
+func (c *Composition) IsEmpty() bool {
+	return c == nil || len(c.Modules) == 0 && len(c.Resources) == 0
+}
+
+// moduleArguments are module block arguments that do not carry data
+var moduleArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"providers":  true,
+	"depends_on": true,
+}
+
+var (
+	moduleReferenceRegex   = regexp.MustCompile(`(?:^|[^\w.])module\.([A-Za-z_][\w-]*)(?:\[[^\]]*\])?\.([A-Za-z_][\w-]*)`)
+	resourceReferenceRegex = regexp.MustCompile(`(?:^|[^\w.])((?:data\.)?[A-Za-z_][\w-]*\.[A-Za-z_][\w-]*)`)
+)
+
+// ParseComposition finds the module calls and resources in the .tf files of a
+// module, and the flows between them derived from the references in module
+// arguments and resource bodies. References between resources are left out.
+func ParseComposition(modulePath string) (*Composition, error) {
+	calls, err := ParseModuleCalls(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list .tf files: %v", err)
+	}
+	sort.Strings(files)
+
+	c := &Composition{Modules: calls}
+	var bodies []string
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", file, err)
+		}
+
+		_, blocks := ParseBody(string(content))
+		for _, block := range blocks {
+			if block.Type != "resource" && block.Type != "data" || len(block.Labels) < 2 {
+				continue
+			}
+
+			r := Resource{Mode: ResourceManaged, Type: block.Labels[0], Name: block.Labels[1]}
+			if block.Type == "data" {
+				r.Mode = ResourceData
+			}
+			// The provider is the resource type up to its first underscore,
+			// unless an aliased configuration such as aws.east is chosen
+			r.Provider = strings.SplitN(r.Type, "_", 2)[0]
+			if provider, ok := AttributeMap(block.Body)["provider"]; ok {
+				r.Provider = strings.SplitN(strings.TrimSpace(provider.Expr), ".", 2)[0]
+			}
+
+			c.Resources = append(c.Resources, r)
+			bodies = append(bodies, block.Body)
+		}
+	}
+
+	modules := make(map[string]bool, len(calls))
+	for _, call := range calls {
+		modules[call.Name] = true
+	}
+	resources := make(map[string]bool, len(c.Resources))
+	for _, r := range c.Resources {
+		resources[r.Address()] = true
+	}
+
+	seen := make(map[Flow]bool)
+	add := func(flow Flow) {
+		if flow.From != flow.To && !seen[flow] {
+			seen[flow] = true
+			c.Flows = append(c.Flows, flow)
+		}
+	}
+
+	// Module outputs and resources passed as module inputs
+	for _, call := range calls {
+		to := "module." + call.Name
+
+		names := make([]string, 0, len(call.Arguments))
+		for name := range call.Arguments {
+			if !moduleArguments[name] {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			expr := call.Arguments[name].Expr
+			for _, ref := range moduleReferenceRegex.FindAllStringSubmatch(expr, -1) {
+				if modules[ref[1]] {
+					add(Flow{From: "module." + ref[1], To: to, Label: ref[2] + " → " + name})
+				}
+			}
+			for _, ref := range resourceReferenceRegex.FindAllStringSubmatch(expr, -1) {
+				if resources[ref[1]] {
+					add(Flow{From: ref[1], To: to, Label: name})
+				}
+			}
+		}
+	}
+
+	// Module outputs used by resources
+	for i, r := range c.Resources {
+		for _, ref := range moduleReferenceRegex.FindAllStringSubmatch(bodies[i], -1) {
+			if modules[ref[1]] {
+				add(Flow{From: "module." + ref[1], To: r.Address(), Label: ref[2]})
+			}
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,76 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseComposition(t *testing.T) {
+	dir, err := ioutil.TempDir("", "composition")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := `
+module "network" {
+  source = "terraform-aws-modules/vpc/aws"
+  name   = var.name
+}
+
+module "cluster" {
+  source     = "./modules/cluster"
+  vpc_id     = module.network.vpc_id
+  subnet_ids = module.network.private_subnets
+  ami        = data.aws_ami.ubuntu.id
+  key        = aws_kms_key.main.arn
+  depends_on = [module.network]
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+
+resource "aws_kms_key" "main" {}
+
+resource "aws_route53_record" "api" {
+  provider = aws.dns
+  records  = [module.cluster[0].endpoint]
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write main.tf: %v", err)
+	}
+
+	c, err := ParseComposition(dir)
+	if err != nil {
+		t.Fatalf("ParseComposition failed: %v", err)
+	}
+
+	if len(c.Modules) != 2 || c.Modules[0].Name != "network" || c.Modules[1].Name != "cluster" {
+		t.Errorf("Unexpected modules: %v", c.Modules)
+	}
+
+	expectedResources := []Resource{
+		{Mode: ResourceData, Type: "aws_ami", Name: "ubuntu", Provider: "aws"},
+		{Mode: ResourceManaged, Type: "aws_kms_key", Name: "main", Provider: "aws"},
+		{Mode: ResourceManaged, Type: "aws_route53_record", Name: "api", Provider: "aws"},
+	}
+	if !reflect.DeepEqual(c.Resources, expectedResources) {
+		t.Errorf("Expected resources %v, got %v", expectedResources, c.Resources)
+	}
+
+	expectedFlows := []Flow{
+		{From: "data.aws_ami.ubuntu", To: "module.cluster", Label: "ami"},
+		{From: "aws_kms_key.main", To: "module.cluster", Label: "key"},
+		{From: "module.network", To: "module.cluster", Label: "private_subnets → subnet_ids"},
+		{From: "module.network", To: "module.cluster", Label: "vpc_id → vpc_id"},
+		{From: "module.cluster", To: "aws_route53_record.api", Label: "endpoint"},
+	}
+	if !reflect.DeepEqual(c.Flows, expectedFlows) {
+		t.Errorf("Expected flows %v, got %v", expectedFlows, c.Flows)
+	}
+}
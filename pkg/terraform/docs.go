@@ -0,0 +1,281 @@
+package terraform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ConfigFileNames lists the terraform-docs configuration files in order of preference
+var ConfigFileNames = []string{
+	".terraform-docs.yml", // Highest priority
+	".terraform-docs.yaml",
+	"terraform-docs.yml",
+	"terraform-docs.yaml", // Lowest priority
+}
+
+// Docs holds everything terraform-docs reports about a single module.
+// It is fetched once per module and shared by the extractor and formatters.
+type Docs struct {
+	Path       string
+	Hash       string
+	ConfigFile string
+	Config     *DocsConfig
+	Header     string
+	Footer     string
+	Markdown   string
+	JSON       map[string]interface{}
+}
+
+var (
+	docsCacheMu sync.Mutex
+	docsCache   = make(map[string]*Docs)
+)
+
+// LoadDocs runs terraform-docs for the module at path, reusing a previous
+// result when the module's files have not changed since it was fetched.
+// The terraform-docs processes are killed when ctx is done.
+func LoadDocs(ctx context.Context, path string) (*Docs, error) {
+	hash, err := HashModule(path)
+	if err != nil {
+		return nil, err
+	}
+
+	docsCacheMu.Lock()
+	cached, ok := docsCache[hash]
+	docsCacheMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	docs := &Docs{
+		Path:       path,
+		Hash:       hash,
+		ConfigFile: FindConfigFile(path),
+	}
+
+	// Read the configuration, header and footer ourselves
+	if docs.Config, err = LoadModuleDocsConfig(path); err != nil {
+		return nil, err
+	}
+	if docs.Header, err = docs.Config.Header(path); err != nil {
+		return nil, err
+	}
+	if docs.Footer, err = docs.Config.Footer(path); err != nil {
+		return nil, err
+	}
+
+	// Run terraform-docs with a configuration of our own, so it neither writes
+	// the module's output file nor renders the header and footer again
+	configFile, err := writeRunConfig(docs.Config)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(configFile)
+
+	// Fetch the JSON document, which carries inputs, outputs, resources and providers
+	output, err := exec.CommandContext(ctx, "terraform-docs", "json", "--config", configFile, path).Output()
+	if err != nil {
+		return nil, runError(ctx, err)
+	}
+	if err := json.Unmarshal(output, &docs.JSON); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform-docs output: %v", err)
+	}
+
+	// Fetch the rendered markdown body
+	output, err = exec.CommandContext(ctx, "terraform-docs", "md", "--config", configFile, path).Output()
+	if err != nil {
+		return nil, runError(ctx, err)
+	}
+	docs.Markdown = string(output)
+
+	docsCacheMu.Lock()
+	docsCache[hash] = docs
+	docsCacheMu.Unlock()
+
+	return docs, nil
+}
+
+var (
+	versionMu   sync.Mutex
+	versionDone bool
+	version     string
+)
+
+// DocsVersion returns the version reported by "terraform-docs --version", or
+// an empty string when terraform-docs cannot be run. It is run once per process.
+func DocsVersion(ctx context.Context) string {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+	if !versionDone {
+		if output, err := exec.CommandContext(ctx, "terraform-docs", "--version").Output(); err == nil {
+			version = strings.TrimSpace(string(output))
+		}
+		versionDone = ctx.Err() == nil
+	}
+	return version
+}
+
+// writeRunConfig writes the configuration terraform-docs runs with to a
+// temporary file and returns its path
+func writeRunConfig(cfg *DocsConfig) (string, error) {
+	content, err := cfg.runConfig()
+	if err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile("", "terraform-docs-*.yml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create terraform-docs configuration: %v", err)
+	}
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write terraform-docs configuration: %v", err)
+	}
+
+	return f.Name(), nil
+}
+
+// runError describes why a terraform-docs process failed
+func runError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("terraform-docs timed out")
+	case context.Canceled:
+		return fmt.Errorf("terraform-docs was interrupted")
+	}
+	return fmt.Errorf("failed to run terraform-docs: %v", err)
+}
+
+// Variables extracts the module inputs reported by terraform-docs
+func (d *Docs) Variables() map[string]Variable {
+	variables := make(map[string]Variable)
+
+	// Navigate the JSON structure to find variables
+	inputs, ok := d.JSON["inputs"].([]interface{})
+	if !ok {
+		return variables
+	}
+
+	for _, input := range inputs {
+		inputMap, ok := input.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := inputMap["name"].(string)
+		if !ok {
+			continue
+		}
+
+		typeExpr, _ := inputMap["type"].(string)
+		typeStr := "any"
+		if typeExpr != "" {
+			typeStr = FormatType(typeExpr)
+		}
+
+		desc := ""
+		if description, ok := inputMap["description"].(string); ok {
+			desc = description
+		}
+
+		// terraform-docs reports whether an input is required, and a null
+		// default for the inputs that are
+		required, ok := inputMap["required"].(bool)
+		if !ok {
+			_, hasDefault := inputMap["default"]
+			required = !hasDefault
+		}
+
+		sensitive, _ := inputMap["sensitive"].(bool)
+
+		variables[name] = Variable{
+			Name:        name,
+			Type:        typeStr,
+			Description: desc,
+			Default:     inputMap["default"],
+			Required:    required,
+			Sensitive:   sensitive,
+			TypeExpr:    strings.TrimSpace(typeExpr),
+		}
+	}
+
+	return variables
+}
+
+// Section returns a top-level section of the terraform-docs JSON document,
+// such as "outputs", "resources" or "providers"
+func (d *Docs) Section(name string) interface{} {
+	return d.JSON[name]
+}
+
+// FindConfigFile returns the terraform-docs configuration file for a module,
+// or an empty string if there is none
+func FindConfigFile(modulePath string) string {
+	for _, name := range ConfigFileNames {
+		path := filepath.Join(modulePath, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// HashModule computes a content hash over a module's .tf files, its
+// terraform-docs configuration and the header and footer files it refers to
+func HashModule(modulePath string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return "", fmt.Errorf("failed to list .tf files: %v", err)
+	}
+	if config := FindConfigFile(modulePath); config != "" {
+		files = append(files, config)
+
+		cfg, err := LoadDocsConfig(config)
+		if err != nil {
+			return "", err
+		}
+		for _, file := range cfg.ContentFiles(modulePath) {
+			if _, err := os.Stat(file); err == nil {
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	absPath, err := filepath.Abs(modulePath)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "module:%s\n", absPath)
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %v", file, err)
+		}
+		fmt.Fprintf(h, "file:%s\n", filepath.Base(file))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %v", file, err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mainTf := filepath.Join(dir, "main.tf")
+	if err := ioutil.WriteFile(mainTf, []byte(`variable "a" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := HashModule(dir)
+	if err != nil {
+		t.Fatalf("HashModule failed: %v", err)
+	}
+
+	second, err := HashModule(dir)
+	if err != nil {
+		t.Fatalf("HashModule failed: %v", err)
+	}
+	if first != second {
+		t.Errorf("Expected identical hashes for unchanged module, got %s and %s", first, second)
+	}
+
+	// Non-Terraform files must not affect the hash
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := HashModule(dir); hash != first {
+		t.Errorf("Expected hash to ignore non-Terraform files")
+	}
+
+	// Changing a .tf file or the config must change the hash
+	if err := ioutil.WriteFile(mainTf, []byte(`variable "b" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, _ := HashModule(dir)
+	if changed == first {
+		t.Errorf("Expected hash to change when a .tf file changes")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, ".terraform-docs.yml"), []byte("formatter: markdown\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := HashModule(dir); hash == changed {
+		t.Errorf("Expected hash to change when the terraform-docs config changes")
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if got := FindConfigFile(dir); got != "" {
+		t.Errorf("Expected no config file, got %s", got)
+	}
+
+	for _, name := range []string{"terraform-docs.yaml", ".terraform-docs.yml"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := filepath.Join(dir, ".terraform-docs.yml")
+	if got := FindConfigFile(dir); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,305 @@
+package terraform
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output modes of a terraform-docs configuration
+const (
+	OutputModeInject  = "inject"
+	OutputModeReplace = "replace"
+)
+
+// DefaultOutputTemplate is the template terraform-docs wraps injected content in
+const DefaultOutputTemplate = "<!-- BEGIN_TF_DOCS -->\n{{ .Content }}\n<!-- END_TF_DOCS -->"
+
+// DocsConfig holds the settings of a terraform-docs configuration file that
+// affect the documentation we render. Unset fields keep terraform-docs' defaults.
+type DocsConfig struct {
+	HeaderFrom string `yaml:"header-from"`
+	FooterFrom string `yaml:"footer-from"`
+
+	Sections struct {
+		Show []string `yaml:"show"`
+		Hide []string `yaml:"hide"`
+	} `yaml:"sections"`
+
+	Sort struct {
+		Enabled *bool  `yaml:"enabled"`
+		By      string `yaml:"by"`
+	} `yaml:"sort"`
+
+	Settings struct {
+		Required *bool `yaml:"required"`
+		Default  *bool `yaml:"default"`
+		Anchor   *bool `yaml:"anchor"`
+		HTML     *bool `yaml:"html"`
+	} `yaml:"settings"`
+
+	Output struct {
+		File     string `yaml:"file"`
+		Mode     string `yaml:"mode"`
+		Template string `yaml:"template"`
+	} `yaml:"output"`
+
+	// File is the path of the configuration file, empty when there is none
+	File string `yaml:"-"`
+	// Unsupported lists the settings of the file that are ignored
+	Unsupported []DocsConfigKey `yaml:"-"`
+}
+
+// DocsConfigKey is a setting of a terraform-docs configuration file, such as
+// "content" or "settings.escape"
+type DocsConfigKey struct {
+	Name string
+	Line int
+}
+
+// docsConfigKeys are the top-level settings that are honoured, or that do
+// not change the documentation rendered
+var docsConfigKeys = map[string]bool{
+	"formatter":   true,
+	"version":     true,
+	"header-from": true,
+	"footer-from": true,
+	"sections":    true,
+	"sort":        true,
+	"settings":    true,
+	"output":      true,
+}
+
+// docsSettingsKeys are the keys under settings that are passed to terraform-docs
+var docsSettingsKeys = map[string]bool{
+	"required": true,
+	"default":  true,
+	"anchor":   true,
+	"html":     true,
+}
+
+// LoadDocsConfig reads the terraform-docs configuration file at path
+func LoadDocsConfig(path string) (*DocsConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	cfg := &DocsConfig{File: path}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	// Settings such as content, header and footer written inline, or
+	// settings.escape would otherwise be dropped without notice
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err == nil && len(doc.Content) > 0 {
+		cfg.Unsupported = unsupportedKeys(doc.Content[0], "", docsConfigKeys)
+		if settings := mappingValue(doc.Content[0], "settings"); settings != nil {
+			cfg.Unsupported = append(cfg.Unsupported, unsupportedKeys(settings, "settings.", docsSettingsKeys)...)
+		}
+	}
+
+	switch cfg.Output.Mode {
+	case "", OutputModeInject, OutputModeReplace:
+	default:
+		return nil, fmt.Errorf("invalid output.mode in %s: %s", path, cfg.Output.Mode)
+	}
+
+	return cfg, nil
+}
+
+// unsupportedKeys returns the keys of a YAML mapping that are not in supported,
+// named with prefix
+func unsupportedKeys(mapping *yaml.Node, prefix string, supported map[string]bool) []DocsConfigKey {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	var keys []DocsConfigKey
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if key := mapping.Content[i]; !supported[key.Value] {
+			keys = append(keys, DocsConfigKey{Name: prefix + key.Value, Line: key.Line})
+		}
+	}
+	return keys
+}
+
+// mappingValue returns the value of key in a YAML mapping, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// LoadModuleDocsConfig reads the terraform-docs configuration of a module,
+// returning an empty configuration when the module has none
+func LoadModuleDocsConfig(modulePath string) (*DocsConfig, error) {
+	path := FindConfigFile(modulePath)
+	if path == "" {
+		return &DocsConfig{}, nil
+	}
+	return LoadDocsConfig(path)
+}
+
+// SortBy returns the order of inputs and outputs: "name", "required" or
+// "type", or an empty string when sorting is disabled
+func (c *DocsConfig) SortBy() string {
+	if c.Sort.Enabled != nil && !*c.Sort.Enabled {
+		return ""
+	}
+	if c.Sort.By == "" {
+		return "name"
+	}
+	return c.Sort.By
+}
+
+// ShowRequired reports whether inputs show whether they are required
+func (c *DocsConfig) ShowRequired() bool {
+	return c.Settings.Required == nil || *c.Settings.Required
+}
+
+// ShowDefault reports whether inputs show their default value
+func (c *DocsConfig) ShowDefault() bool {
+	return c.Settings.Default == nil || *c.Settings.Default
+}
+
+// RowAnchors reports whether the tables of inputs and outputs carry anchors
+// such as <a name="input_region"></a>, which needs both settings.anchor and
+// settings.html
+func (c *DocsConfig) RowAnchors() bool {
+	return (c.Settings.Anchor == nil || *c.Settings.Anchor) && (c.Settings.HTML == nil || *c.Settings.HTML)
+}
+
+// ShowSection reports whether the named section is shown by sections.show and
+// sections.hide; "all" in either list applies to every section
+func (c *DocsConfig) ShowSection(name string) bool {
+	if len(c.Sections.Show) > 0 && !containsSection(c.Sections.Show, name) {
+		return false
+	}
+	return !containsSection(c.Sections.Hide, name)
+}
+
+func containsSection(sections []string, name string) bool {
+	for _, section := range sections {
+		if section == "all" || strings.EqualFold(section, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Header reads the module header from the header-from file, main.tf by default
+func (c *DocsConfig) Header(modulePath string) (string, error) {
+	from := c.HeaderFrom
+	if from == "" {
+		from = "main.tf"
+	}
+	return readContentFrom(modulePath, from, c.HeaderFrom != "")
+}
+
+// Footer reads the module footer from the footer-from file, if one is set
+func (c *DocsConfig) Footer(modulePath string) (string, error) {
+	if c.FooterFrom == "" {
+		return "", nil
+	}
+	return readContentFrom(modulePath, c.FooterFrom, true)
+}
+
+// ContentFiles returns the header and footer files outside the module's .tf
+// files, which also determine the rendered documentation
+func (c *DocsConfig) ContentFiles(modulePath string) []string {
+	var files []string
+	for _, from := range []string{c.HeaderFrom, c.FooterFrom} {
+		if from != "" && filepath.Ext(from) != ".tf" {
+			files = append(files, filepath.Join(modulePath, from))
+		}
+	}
+	return files
+}
+
+// readContentFrom reads a header or footer file relative to the module. A .tf
+// file contributes the block comment at its top; any other file is used as is.
+// A missing file is only an error when it was configured explicitly.
+func readContentFrom(modulePath string, from string, required bool) (string, error) {
+	path := filepath.Join(modulePath, from)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	if filepath.Ext(from) != ".tf" {
+		return strings.TrimSpace(string(content)), nil
+	}
+	return leadingBlockComment(string(content)), nil
+}
+
+// leadingBlockComment extracts the /* ... */ comment at the top of a .tf file,
+// stripping the comment markers and the leading "*" of each line
+func leadingBlockComment(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "/*") {
+		return ""
+	}
+	end := strings.Index(trimmed, "*/")
+	if end < 0 {
+		return ""
+	}
+	body := strings.TrimLeft(trimmed[2:end], "*")
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "*") {
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "*"), " ")
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// runConfig returns the configuration passed to terraform-docs in place of the
+// module's own file. It keeps the rendering settings but leaves out the header,
+// footer and output file, which we handle ourselves.
+func (c *DocsConfig) runConfig() ([]byte, error) {
+	sort := map[string]interface{}{"enabled": false}
+	if by := c.SortBy(); by != "" {
+		sort = map[string]interface{}{"enabled": true, "by": by}
+	}
+
+	run := map[string]interface{}{
+		"sections": map[string]interface{}{
+			"hide": []string{"header", "footer"},
+		},
+		"sort": sort,
+		// Anchors and HTML are on unless turned off, as the usage examples
+		// link to the input anchors
+		"settings": map[string]interface{}{
+			"required": c.ShowRequired(),
+			"default":  c.ShowDefault(),
+			"anchor":   c.Settings.Anchor == nil || *c.Settings.Anchor,
+			"html":     c.Settings.HTML == nil || *c.Settings.HTML,
+		},
+	}
+
+	content, err := yaml.Marshal(run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode terraform-docs configuration: %v", err)
+	}
+	return content, nil
+}
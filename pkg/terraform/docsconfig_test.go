@@ -0,0 +1,123 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadModuleDocsConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-docsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		".terraform-docs.yml": `
+formatter: markdown table
+footer-from: FOOTER.md
+sections:
+  hide: [providers, requirements]
+sort:
+  by: required
+settings:
+  default: false
+  anchor: false
+  escape: false
+output:
+  file: README.md
+  mode: inject
+`,
+		"main.tf": `/**
+ * # Network
+ *
+ * Creates the network.
+ */
+
+resource "null_resource" "this" {}
+`,
+		"FOOTER.md": "## License\n\nMIT\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := LoadModuleDocsConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadModuleDocsConfig failed: %v", err)
+	}
+
+	if cfg.SortBy() != "required" {
+		t.Errorf("Expected sorting by required, got %q", cfg.SortBy())
+	}
+	if !cfg.ShowRequired() || cfg.ShowDefault() {
+		t.Errorf("Expected the required column shown and the default column hidden")
+	}
+	if cfg.ShowSection("providers") || !cfg.ShowSection("inputs") {
+		t.Errorf("Expected providers hidden and inputs shown")
+	}
+	if cfg.Output.File != "README.md" || cfg.Output.Mode != OutputModeInject {
+		t.Errorf("Expected output settings to be read, got %+v", cfg.Output)
+	}
+	if cfg.RowAnchors() {
+		t.Errorf("Expected settings.anchor to turn off the row anchors")
+	}
+	if run, err := cfg.runConfig(); err != nil || !strings.Contains(string(run), "anchor: false") {
+		t.Errorf("Expected settings.anchor to be passed to terraform-docs, got:\n%s (%v)", run, err)
+	}
+	if !reflect.DeepEqual(cfg.Unsupported, []DocsConfigKey{{Name: "settings.escape", Line: 11}}) {
+		t.Errorf("Expected settings.escape to be reported as unsupported, got %+v", cfg.Unsupported)
+	}
+
+	header, err := cfg.Header(dir)
+	if err != nil || header != "# Network\n\nCreates the network." {
+		t.Errorf("Expected the header from the main.tf comment, got %q (%v)", header, err)
+	}
+	footer, err := cfg.Footer(dir)
+	if err != nil || footer != "## License\n\nMIT" {
+		t.Errorf("Expected the footer from FOOTER.md, got %q (%v)", footer, err)
+	}
+
+	// The footer file is part of the module hash
+	before, err := HashModule(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "FOOTER.md"), []byte("## License\n\nApache 2.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := HashModule(dir); after == before {
+		t.Errorf("Expected the hash to change with the footer file")
+	}
+}
+
+func TestLoadDocsConfigDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfdocs-docsconfig-none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := LoadModuleDocsConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadModuleDocsConfig failed: %v", err)
+	}
+	if cfg.SortBy() != "name" || !cfg.ShowRequired() || !cfg.ShowDefault() || !cfg.ShowSection("inputs") {
+		t.Errorf("Expected terraform-docs defaults without a configuration file")
+	}
+	if header, err := cfg.Header(dir); err != nil || header != "" {
+		t.Errorf("Expected no header without main.tf, got %q (%v)", header, err)
+	}
+
+	// An explicitly configured header file must exist
+	cfg.HeaderFrom = "HEADER.md"
+	if _, err := cfg.Header(dir); err == nil {
+		t.Errorf("Expected an error for a missing header-from file")
+	}
+}
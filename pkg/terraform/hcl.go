@@ -0,0 +1,341 @@
+package terraform
+
+import (
+	"strings"
+)
+
+// Block represents an HCL block such as a variable, module or resource definition
+type Block struct {
+	Type   string
+	Labels []string
+	Body   string
+	Line   int
+}
+
+// Attribute represents an argument assignment inside an HCL body
+type Attribute struct {
+	Name string
+	Expr string
+	Line int
+}
+
+// ParseBody splits HCL content into its top-level attributes and blocks.
+// Nested blocks are returned with their raw body so they can be parsed in turn.
+func ParseBody(content string) ([]Attribute, []Block) {
+	var attrs []Attribute
+	var blocks []Block
+
+	s := &scanner{src: content, line: 1}
+	for {
+		s.skipSpace(true)
+		if s.eof() {
+			break
+		}
+
+		line := s.line
+		ident := s.readIdent()
+		if ident == "" {
+			// Not a statement we understand; skip to the next line
+			s.skipExpr()
+			continue
+		}
+
+		s.skipSpace(false)
+		if s.peek() == '=' && s.peekAt(1) != '=' {
+			s.pos++
+			attrs = append(attrs, Attribute{
+				Name: ident,
+				Expr: strings.TrimSpace(s.skipExpr()),
+				Line: line,
+			})
+			continue
+		}
+
+		block := Block{Type: ident, Line: line}
+		for !s.eof() {
+			s.skipSpace(false)
+			if s.peek() == '"' {
+				label := s.readString()
+				block.Labels = append(block.Labels, strings.Trim(label, "\""))
+			} else if ident := s.readIdent(); ident != "" {
+				block.Labels = append(block.Labels, ident)
+			} else {
+				break
+			}
+		}
+
+		if s.peek() != '{' {
+			s.skipExpr()
+			continue
+		}
+		s.pos++
+		start := s.pos
+		if s.skipBalanced('}') {
+			block.Body = s.src[start : s.pos-1]
+		} else {
+			block.Body = s.src[start:]
+		}
+		blocks = append(blocks, block)
+	}
+
+	return attrs, blocks
+}
+
+// ParseBlocks returns the top-level blocks of the given type in content
+func ParseBlocks(content string, blockType string) []Block {
+	_, blocks := ParseBody(content)
+
+	var result []Block
+	for _, block := range blocks {
+		if block.Type == blockType {
+			result = append(result, block)
+		}
+	}
+	return result
+}
+
+// AttributeMap indexes the top-level attributes of an HCL body by name
+func AttributeMap(body string) map[string]Attribute {
+	attrs, _ := ParseBody(body)
+
+	result := make(map[string]Attribute, len(attrs))
+	for _, attr := range attrs {
+		result[attr.Name] = attr
+	}
+	return result
+}
+
+// UnquoteString returns the value of a quoted string literal expression,
+// and false if the expression is not a plain string literal
+func UnquoteString(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	if len(expr) < 2 || expr[0] != '"' || expr[len(expr)-1] != '"' {
+		return "", false
+	}
+	inner := expr[1 : len(expr)-1]
+	if strings.Contains(inner, "${") || strings.Contains(inner, "%{") {
+		return "", false
+	}
+	inner = strings.ReplaceAll(inner, `\"`, `"`)
+	inner = strings.ReplaceAll(inner, `\\`, `\`)
+	return inner, true
+}
+
+// scanner walks HCL source while keeping track of strings, comments and nesting
+type scanner struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *scanner) peek() byte {
+	return s.peekAt(0)
+}
+
+func (s *scanner) peekAt(offset int) byte {
+	if s.pos+offset >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos+offset]
+}
+
+func (s *scanner) advance() byte {
+	c := s.src[s.pos]
+	s.pos++
+	if c == '\n' {
+		s.line++
+	}
+	return c
+}
+
+// skipSpace skips whitespace and comments, optionally crossing newlines
+func (s *scanner) skipSpace(newlines bool) {
+	for !s.eof() {
+		c := s.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			s.advance()
+		case c == '\n' && newlines:
+			s.advance()
+		case c == '#' || (c == '/' && s.peekAt(1) == '/'):
+			for !s.eof() && s.peek() != '\n' {
+				s.advance()
+			}
+		case c == '/' && s.peekAt(1) == '*':
+			s.skipBlockComment()
+		default:
+			return
+		}
+	}
+}
+
+func (s *scanner) skipBlockComment() {
+	s.pos += 2
+	for !s.eof() {
+		if s.peek() == '*' && s.peekAt(1) == '/' {
+			s.pos += 2
+			return
+		}
+		s.advance()
+	}
+}
+
+// readIdent reads an identifier, returning an empty string if there is none
+func (s *scanner) readIdent() string {
+	start := s.pos
+	for !s.eof() {
+		c := s.peek()
+		letter := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if letter || (s.pos > start && (c == '-' || c >= '0' && c <= '9')) {
+			s.pos++
+			continue
+		}
+		break
+	}
+	return s.src[start:s.pos]
+}
+
+// readString reads a quoted string including its quotes and any interpolations
+func (s *scanner) readString() string {
+	start := s.pos
+	s.advance()
+	for !s.eof() {
+		c := s.advance()
+		switch {
+		case c == '\\':
+			if !s.eof() {
+				s.advance()
+			}
+		case c == '"':
+			return s.src[start:s.pos]
+		case (c == '$' || c == '%') && s.peek() == '{':
+			s.advance()
+			s.skipBalanced('}')
+		case c == '\n':
+			// Unterminated string; stop at the end of the line
+			return s.src[start:s.pos]
+		}
+	}
+	return s.src[start:s.pos]
+}
+
+// skipHeredoc skips a heredoc starting at "<<" and returns false if there is none
+func (s *scanner) skipHeredoc() bool {
+	rest := s.src[s.pos:]
+	if !strings.HasPrefix(rest, "<<") {
+		return false
+	}
+	offset := 2
+	if strings.HasPrefix(rest[offset:], "-") {
+		offset++
+	}
+	end := strings.IndexByte(rest[offset:], '\n')
+	if end < 0 {
+		return false
+	}
+	marker := strings.TrimSpace(rest[offset : offset+end])
+	if marker == "" || strings.ContainsAny(marker, " \t\"") {
+		return false
+	}
+
+	s.pos += offset + end
+	for !s.eof() {
+		s.advance() // newline
+		lineEnd := strings.IndexByte(s.src[s.pos:], '\n')
+		line := s.src[s.pos:]
+		if lineEnd >= 0 {
+			line = line[:lineEnd]
+		}
+		if strings.TrimSpace(line) == marker {
+			s.pos += len(line)
+			return true
+		}
+		for !s.eof() && s.peek() != '\n' {
+			s.advance()
+		}
+	}
+	return true
+}
+
+// skipBalanced advances past the closing character matching an already
+// consumed opening bracket, returning false if the input ends first
+func (s *scanner) skipBalanced(closing byte) bool {
+	stack := []byte{closing}
+	for !s.eof() {
+		c := s.peek()
+		switch {
+		case c == '"':
+			s.readString()
+			continue
+		case c == '#' || (c == '/' && (s.peekAt(1) == '/' || s.peekAt(1) == '*')):
+			s.skipSpace(false)
+			continue
+		case c == '<' && s.peekAt(1) == '<':
+			if s.skipHeredoc() {
+				continue
+			}
+		case c == '{':
+			stack = append(stack, '}')
+		case c == '(':
+			stack = append(stack, ')')
+		case c == '[':
+			stack = append(stack, ']')
+		case c == stack[len(stack)-1]:
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				s.advance()
+				return true
+			}
+		}
+		s.advance()
+	}
+	return false
+}
+
+// skipExpr advances to the end of the current expression, which ends at the
+// first newline outside of any brackets, and returns its source text
+func (s *scanner) skipExpr() string {
+	start := s.pos
+	for !s.eof() {
+		c := s.peek()
+		switch {
+		case c == '\n':
+			text := s.src[start:s.pos]
+			s.advance()
+			return text
+		case c == '"':
+			s.readString()
+			continue
+		case c == '#' || (c == '/' && s.peekAt(1) == '/'):
+			// A line comment ends the expression
+			text := s.src[start:s.pos]
+			s.skipSpace(false)
+			return text
+		case c == '/' && s.peekAt(1) == '*':
+			s.skipBlockComment()
+			continue
+		case c == '<' && s.peekAt(1) == '<':
+			if s.skipHeredoc() {
+				continue
+			}
+		case c == '{':
+			s.advance()
+			s.skipBalanced('}')
+			continue
+		case c == '(':
+			s.advance()
+			s.skipBalanced(')')
+			continue
+		case c == '[':
+			s.advance()
+			s.skipBalanced(']')
+			continue
+		}
+		s.advance()
+	}
+	return s.src[start:s.pos]
+}
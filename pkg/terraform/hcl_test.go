@@ -0,0 +1,89 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBody(t *testing.T) {
+	content := `
+# A comment with a { brace
+module "network" {
+  source = "./modules/network" // trailing comment
+  cidr   = "10.0.0.0/16"
+  tags = {
+    Name = "main-${var.env}"
+  }
+
+  lifecycle {
+    ignore = ["}"]
+  }
+}
+
+locals {
+  script = <<-EOT
+    echo "}"
+  EOT
+}
+
+region = "us-east-1"
+`
+
+	attrs, blocks := ParseBody(content)
+
+	if len(attrs) != 1 || attrs[0].Name != "region" || attrs[0].Expr != `"us-east-1"` {
+		t.Errorf("Expected a single region attribute, got %+v", attrs)
+	}
+
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d: %+v", len(blocks), blocks)
+	}
+
+	module := blocks[0]
+	if module.Type != "module" || !reflect.DeepEqual(module.Labels, []string{"network"}) {
+		t.Errorf("Unexpected module block: %+v", module)
+	}
+	if module.Line != 3 {
+		t.Errorf("Expected module block on line 3, got %d", module.Line)
+	}
+
+	args, nested := ParseBody(module.Body)
+	names := []string{}
+	for _, attr := range args {
+		names = append(names, attr.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"source", "cidr", "tags"}) {
+		t.Errorf("Unexpected module arguments: %v", names)
+	}
+	if args[0].Expr != `"./modules/network"` {
+		t.Errorf("Expected comment to be stripped from source, got %s", args[0].Expr)
+	}
+	if len(nested) != 1 || nested[0].Type != "lifecycle" {
+		t.Errorf("Expected a nested lifecycle block, got %+v", nested)
+	}
+
+	if blocks[1].Type != "locals" {
+		t.Errorf("Expected locals block after heredoc, got %+v", blocks[1])
+	}
+}
+
+func TestUnquoteString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{`"plain"`, "plain", true},
+		{`"with \"quotes\""`, `with "quotes"`, true},
+		{`"interp-${var.x}"`, "", false},
+		{`var.x`, "", false},
+		{`42`, "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := UnquoteString(test.input)
+		if got != test.expected || ok != test.ok {
+			t.Errorf("UnquoteString(%s) = %q, %v; expected %q, %v", test.input, got, ok, test.expected, test.ok)
+		}
+	}
+}
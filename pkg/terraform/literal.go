@@ -0,0 +1,223 @@
+package terraform
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeLiteral decodes a literal HCL expression into the values
+// encoding/json produces: strings, float64 numbers, booleans, nil, slices
+// and maps. It returns false for expressions that are not literals, such as
+// references, function calls and strings with interpolations.
+func DecodeLiteral(expr string) (interface{}, bool) {
+	s := &scanner{src: expr, line: 1}
+	value, ok := decodeValue(s)
+	if !ok {
+		return nil, false
+	}
+	s.skipSpace(true)
+	return value, s.eof()
+}
+
+// decodeValue decodes the literal at the scanner position
+func decodeValue(s *scanner) (interface{}, bool) {
+	s.skipSpace(true)
+	if s.eof() {
+		return nil, false
+	}
+
+	switch c := s.peek(); {
+	case c == '"':
+		return decodeString(s.readString())
+	case c == '<' && s.peekAt(1) == '<':
+		return decodeHeredoc(s)
+	case c == '[':
+		return decodeList(s)
+	case c == '{':
+		return decodeObject(s)
+	case c == '-' || c >= '0' && c <= '9':
+		start := s.pos
+		s.advance()
+		for !s.eof() && strings.IndexByte("0123456789.eE+-", s.peek()) >= 0 {
+			s.advance()
+		}
+		number, err := strconv.ParseFloat(s.src[start:s.pos], 64)
+		return number, err == nil
+	}
+
+	switch s.readIdent() {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	return nil, false
+}
+
+// decodeString decodes a quoted string literal without interpolations
+func decodeString(quoted string) (interface{}, bool) {
+	if len(quoted) < 2 || quoted[len(quoted)-1] != '"' {
+		return nil, false
+	}
+
+	var sb strings.Builder
+	inner := quoted[1 : len(quoted)-1]
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case (c == '$' || c == '%') && strings.HasPrefix(inner[i+1:], string(c)+"{"):
+			// An escaped template sequence such as $${
+			sb.WriteString(string(c) + "{")
+			i += 2
+		case (c == '$' || c == '%') && strings.HasPrefix(inner[i+1:], "{"):
+			return nil, false
+		case c == '\\' && i+1 < len(inner):
+			i++
+			switch inner[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'u', 'U':
+				size := 4
+				if inner[i] == 'U' {
+					size = 8
+				}
+				if i+size >= len(inner) {
+					return nil, false
+				}
+				code, err := strconv.ParseUint(inner[i+1:i+1+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return nil, false
+				}
+				sb.WriteRune(rune(code))
+				i += size
+			default:
+				sb.WriteByte(inner[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), true
+}
+
+// decodeHeredoc decodes a heredoc without interpolations. The indented form
+// "<<-" has the indentation common to all lines removed.
+func decodeHeredoc(s *scanner) (interface{}, bool) {
+	start := s.pos
+	if !s.skipHeredoc() {
+		return nil, false
+	}
+	text := s.src[start:s.pos]
+
+	lines := strings.Split(text, "\n")
+	if len(lines) < 2 {
+		return nil, false
+	}
+	indented := strings.HasPrefix(lines[0], "<<-")
+	body := lines[1 : len(lines)-1]
+
+	if indented {
+		common := -1
+		for _, line := range body {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			if common < 0 || indent < common {
+				common = indent
+			}
+		}
+		for i, line := range body {
+			if len(line) >= common && common > 0 {
+				body[i] = line[common:]
+			}
+		}
+	}
+
+	value := strings.Join(body, "\n")
+	if len(body) > 0 {
+		value += "\n"
+	}
+	if strings.Contains(strings.ReplaceAll(value, "$${", ""), "${") || strings.Contains(strings.ReplaceAll(value, "%%{", ""), "%{") {
+		return nil, false
+	}
+	value = strings.ReplaceAll(value, "$${", "${")
+	return strings.ReplaceAll(value, "%%{", "%{"), true
+}
+
+// decodeList decodes a tuple literal such as ["a", "b"]
+func decodeList(s *scanner) (interface{}, bool) {
+	s.advance()
+	items := []interface{}{}
+	for {
+		s.skipSpace(true)
+		if s.peek() == ']' {
+			s.advance()
+			return items, true
+		}
+
+		item, ok := decodeValue(s)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, item)
+
+		s.skipSpace(true)
+		switch s.peek() {
+		case ',':
+			s.advance()
+		case ']':
+		default:
+			return nil, false
+		}
+	}
+}
+
+// decodeObject decodes an object literal such as { a = 1, "b" = 2 }
+func decodeObject(s *scanner) (interface{}, bool) {
+	s.advance()
+	object := map[string]interface{}{}
+	for {
+		s.skipSpace(true)
+		if s.peek() == '}' {
+			s.advance()
+			return object, true
+		}
+
+		var key string
+		if s.peek() == '"' {
+			decoded, ok := decodeString(s.readString())
+			if !ok {
+				return nil, false
+			}
+			key = decoded.(string)
+		} else if key = s.readIdent(); key == "" {
+			return nil, false
+		}
+
+		s.skipSpace(false)
+		if c := s.peek(); c != '=' && c != ':' {
+			return nil, false
+		}
+		s.advance()
+
+		value, ok := decodeValue(s)
+		if !ok {
+			return nil, false
+		}
+		object[key] = value
+
+		// Items are separated by commas or newlines
+		s.skipSpace(false)
+		if s.peek() == ',' {
+			s.advance()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package terraform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleCall represents a module block that calls another module
+type ModuleCall struct {
+	Name      string
+	Source    string
+	Version   string
+	File      string
+	Line      int
+	Arguments map[string]Attribute
+}
+
+// ParseModuleCalls finds every module block in the .tf files of a module
+func ParseModuleCalls(modulePath string) ([]ModuleCall, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list .tf files: %v", err)
+	}
+	sort.Strings(files)
+
+	var calls []ModuleCall
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", file, err)
+		}
+
+		for _, block := range ParseBlocks(string(content), "module") {
+			if len(block.Labels) == 0 {
+				continue
+			}
+
+			call := ModuleCall{
+				Name:      block.Labels[0],
+				File:      file,
+				Line:      block.Line,
+				Arguments: AttributeMap(block.Body),
+			}
+			if source, ok := call.Arguments["source"]; ok {
+				call.Source, _ = UnquoteString(source.Expr)
+			}
+			if version, ok := call.Arguments["version"]; ok {
+				call.Version, _ = UnquoteString(version.Expr)
+			}
+
+			calls = append(calls, call)
+		}
+	}
+
+	return calls, nil
+}
+
+// IsLocalSource reports whether a module source refers to a local directory
+func IsLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// IsRegistryAddress reports whether a module source is a registry address,
+// such as "hashicorp/consul/aws" or "app.terraform.io/org/vpc/aws"
+func IsRegistryAddress(source string) bool {
+	if strings.ContainsAny(source, ":?") || IsLocalSource(source) {
+		return false
+	}
+	parts := strings.Split(source, "/")
+	for _, part := range parts {
+		if part == "" {
+			return false
+		}
+	}
+	switch len(parts) {
+	case 3:
+		// A namespace has no dots, unlike shorthands such as github.com/org/repo
+		return !strings.Contains(parts[0], ".")
+	case 4:
+		return strings.Contains(parts[0], ".")
+	}
+	return false
+}
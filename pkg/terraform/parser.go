@@ -1,10 +1,9 @@
 package terraform
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -17,65 +16,43 @@ type Variable struct {
 	Description string      `json:"description"`
 	Default     interface{} `json:"default"`
 	Required    bool        `json:"required"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+
+	// TypeExpr is the complete type constraint as written, line breaks included;
+	// Type may be shortened for display
+	TypeExpr string `json:"type_expr,omitempty"`
+	// Enum lists the only values the variable's validation rules accept
+	Enum []interface{} `json:"enum,omitempty"`
+	// Group is the category named by a "# @group" comment
+	Group string `json:"group,omitempty"`
 }
 
-// ExtractTerraformDocsInfo runs terraform-docs and extracts variable info
-func ExtractTerraformDocsInfo(path string) (map[string]Variable, error) {
-	cmd := exec.Command("terraform-docs", "json", path)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to run terraform-docs: %v", err)
-	}
+// FileError reports a problem with a specific Terraform file
+type FileError struct {
+	File string
+	Line int
+	Err  error
+}
 
-	var tfDocsOutput map[string]interface{}
-	if err := json.Unmarshal(output, &tfDocsOutput); err != nil {
-		return nil, fmt.Errorf("failed to parse terraform-docs output: %v", err)
+func (e *FileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
 	}
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
 
-	// Extract variables from terraform-docs output
-	variables := make(map[string]Variable)
-
-	// Navigate the JSON structure to find variables
-	if inputs, ok := tfDocsOutput["inputs"].([]interface{}); ok {
-		for _, input := range inputs {
-			inputMap, ok := input.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			name, ok := inputMap["name"].(string)
-			if !ok {
-				continue
-			}
-			
-			typeStr, ok := inputMap["type"].(string)
-			if !ok {
-				typeStr = "any"
-			} else {
-				typeStr = FormatType(typeStr)
-			}
-			
-			desc := ""
-			if description, ok := inputMap["description"].(string); ok {
-				desc = description
-			}
-
-			hasDefault := false
-			if _, ok := inputMap["default"]; ok {
-				hasDefault = true
-			}
+// Unwrap returns the underlying error
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
 
-			variables[name] = Variable{
-				Name:        name,
-				Type:        typeStr,
-				Description: desc,
-				Default:     inputMap["default"],
-				Required:    !hasDefault,
-			}
-		}
+// ExtractTerraformDocsInfo runs terraform-docs and extracts variable info
+func ExtractTerraformDocsInfo(ctx context.Context, path string) (map[string]Variable, error) {
+	docs, err := LoadDocs(ctx, path)
+	if err != nil {
+		return nil, err
 	}
-
-	return variables, nil
+	return docs.Variables(), nil
 }
 
 // ParseModuleFiles parses Terraform module files directly for better variable type extraction
@@ -92,13 +69,13 @@ func ParseModuleFiles(modulePath string) (map[string]Variable, error) {
 	for _, file := range files {
 		content, err := ioutil.ReadFile(file)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %v", file, err)
+			return nil, &FileError{File: file, Err: err}
 		}
 		
 		// Parse variables from the file
 		fileVars, err := ParseVariablesFromContent(string(content))
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse variables from %s: %v", file, err)
+			return nil, &FileError{File: file, Err: err}
 		}
 		
 		// Merge with existing variables
@@ -155,9 +132,27 @@ func ParseVariablesFromContent(content string) (map[string]Variable, error) {
 		hasDefault := defaultRegex.MatchString(blockContent)
 		variable.Required = !hasDefault
 		
+		// Check whether the value is sensitive
+		sensitiveRegex := regexp.MustCompile(`(?m)sensitive\s*=\s*true\b`)
+		variable.Sensitive = sensitiveRegex.MatchString(blockContent)
+		
 		variables[name] = variable
 	}
 	
+	// Read what the regular expressions cannot see from the complete blocks
+	for _, block := range ParseBlocks(content, "variable") {
+		if len(block.Labels) == 0 {
+			continue
+		}
+		variable, ok := variables[block.Labels[0]]
+		if !ok {
+			continue
+		}
+		describeVariable(&variable, block)
+		variable.Group = commentGroup(content, block)
+		variables[variable.Name] = variable
+	}
+	
 	return variables, nil
 }
 
@@ -0,0 +1,66 @@
+package terraform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleProviders returns the providers a module uses: the names declared in
+// its required_providers blocks or, when there are none, the providers implied
+// by its resource and data source types, most used first
+func ModuleProviders(modulePath string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list .tf files: %v", err)
+	}
+	sort.Strings(files)
+
+	var required []string
+	counts := make(map[string]int)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", file, err)
+		}
+
+		for _, block := range ParseBlocks(string(content), "terraform") {
+			for _, providers := range ParseBlocks(block.Body, "required_providers") {
+				for name := range AttributeMap(providers.Body) {
+					required = append(required, name)
+				}
+			}
+		}
+
+		for _, blockType := range []string{"resource", "data"} {
+			for _, block := range ParseBlocks(string(content), blockType) {
+				if len(block.Labels) == 0 {
+					continue
+				}
+				// The provider is the resource type up to its first underscore
+				provider := strings.SplitN(block.Labels[0], "_", 2)[0]
+				counts[provider]++
+			}
+		}
+	}
+
+	if len(required) > 0 {
+		sort.Strings(required)
+		return required, nil
+	}
+
+	providers := make([]string, 0, len(counts))
+	for provider := range counts {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		if counts[providers[i]] != counts[providers[j]] {
+			return counts[providers[i]] > counts[providers[j]]
+		}
+		return providers[i] < providers[j]
+	})
+
+	return providers, nil
+}
@@ -0,0 +1,225 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of Type
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+	TypeAny    = "any"
+	TypeList   = "list"
+	TypeSet    = "set"
+	TypeMap    = "map"
+	TypeTuple  = "tuple"
+	TypeObject = "object"
+)
+
+// Type is a parsed Terraform type constraint
+type Type struct {
+	Kind string
+	// Elem is the element type of lists, sets and maps
+	Elem *Type
+	// Elems are the element types of a tuple
+	Elems []*Type
+	// Attributes are the attributes of an object, in declaration order
+	Attributes []TypeAttribute
+}
+
+// TypeAttribute is an attribute of an object type
+type TypeAttribute struct {
+	Name string
+	Type *Type
+	// Optional is set for attributes declared with optional(); Default is
+	// their default value, if one was given and it is a literal
+	Optional   bool
+	Default    interface{}
+	HasDefault bool
+}
+
+// ParseType parses a type constraint such as
+// "list(object({ name = string, size = optional(number, 1) }))". An empty
+// expression is the type any.
+func ParseType(expr string) (*Type, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return &Type{Kind: TypeAny}, nil
+	}
+
+	// Terraform 0.11 quoted the type keyword
+	if unquoted, ok := UnquoteString(expr); ok {
+		expr = unquoted
+	}
+
+	s := &scanner{src: expr, line: 1}
+	t, err := parseType(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %v", expr, err)
+	}
+	s.skipSpace(true)
+	if !s.eof() {
+		return nil, fmt.Errorf("invalid type %q: unexpected %q", expr, s.src[s.pos:])
+	}
+	return t, nil
+}
+
+// parseType parses the type at the scanner position
+func parseType(s *scanner) (*Type, error) {
+	s.skipSpace(true)
+	keyword := s.readIdent()
+
+	switch keyword {
+	case TypeString, TypeNumber, TypeBool, TypeAny:
+		return &Type{Kind: keyword}, nil
+
+	case TypeList, TypeSet, TypeMap:
+		// A bare collection keyword is shorthand for a collection of any
+		s.skipSpace(true)
+		if s.peek() != '(' {
+			return &Type{Kind: keyword, Elem: &Type{Kind: TypeAny}}, nil
+		}
+		s.advance()
+		elem, err := parseType(s)
+		if err != nil {
+			return nil, err
+		}
+		if err := expect(s, ')'); err != nil {
+			return nil, err
+		}
+		return &Type{Kind: keyword, Elem: elem}, nil
+
+	case TypeTuple:
+		if err := expect(s, '('); err != nil {
+			return nil, err
+		}
+		if err := expect(s, '['); err != nil {
+			return nil, err
+		}
+		t := &Type{Kind: TypeTuple}
+		for {
+			s.skipSpace(true)
+			if s.peek() == ']' {
+				s.advance()
+				break
+			}
+			elem, err := parseType(s)
+			if err != nil {
+				return nil, err
+			}
+			t.Elems = append(t.Elems, elem)
+			if err := separator(s, ']'); err != nil {
+				return nil, err
+			}
+		}
+		return t, expect(s, ')')
+
+	case TypeObject:
+		if err := expect(s, '('); err != nil {
+			return nil, err
+		}
+		if err := expect(s, '{'); err != nil {
+			return nil, err
+		}
+		t := &Type{Kind: TypeObject}
+		for {
+			s.skipSpace(true)
+			if s.peek() == '}' {
+				s.advance()
+				break
+			}
+			attr, err := parseTypeAttribute(s)
+			if err != nil {
+				return nil, err
+			}
+			t.Attributes = append(t.Attributes, attr)
+			if err := separator(s, '}'); err != nil {
+				return nil, err
+			}
+		}
+		return t, expect(s, ')')
+	}
+
+	if keyword == "" {
+		return nil, fmt.Errorf("expected a type at %q", s.src[s.pos:])
+	}
+	return nil, fmt.Errorf("unknown type %q", keyword)
+}
+
+// parseTypeAttribute parses "name = type" inside an object type
+func parseTypeAttribute(s *scanner) (TypeAttribute, error) {
+	var attr TypeAttribute
+	if s.peek() == '"' {
+		attr.Name, _ = UnquoteString(s.readString())
+	} else {
+		attr.Name = s.readIdent()
+	}
+	if attr.Name == "" {
+		return attr, fmt.Errorf("expected an attribute name at %q", s.src[s.pos:])
+	}
+
+	s.skipSpace(false)
+	if c := s.peek(); c != '=' && c != ':' {
+		return attr, fmt.Errorf("expected = after attribute %q", attr.Name)
+	}
+	s.advance()
+	s.skipSpace(true)
+
+	// optional(type) and optional(type, default)
+	start := s.pos
+	if s.readIdent() != "optional" {
+		s.pos = start
+		t, err := parseType(s)
+		attr.Type = t
+		return attr, err
+	}
+
+	attr.Optional = true
+	if err := expect(s, '('); err != nil {
+		return attr, err
+	}
+	t, err := parseType(s)
+	if err != nil {
+		return attr, err
+	}
+	attr.Type = t
+
+	s.skipSpace(true)
+	if s.peek() == ',' {
+		s.advance()
+		start := s.pos
+		if !s.skipBalanced(')') {
+			return attr, fmt.Errorf("unterminated optional() for attribute %q", attr.Name)
+		}
+		// skipBalanced consumed the closing parenthesis of optional()
+		attr.Default, attr.HasDefault = DecodeLiteral(s.src[start : s.pos-1])
+		return attr, nil
+	}
+	return attr, expect(s, ')')
+}
+
+// expect consumes the given character after optional whitespace
+func expect(s *scanner, c byte) error {
+	s.skipSpace(true)
+	if s.peek() != c {
+		return fmt.Errorf("expected %q at %q", c, s.src[s.pos:])
+	}
+	s.advance()
+	return nil
+}
+
+// separator consumes the comma or newline after a list item, leaving the
+// closing character in place
+func separator(s *scanner, closing byte) error {
+	s.skipSpace(false)
+	switch s.peek() {
+	case ',':
+		s.advance()
+	case '\n', closing:
+	default:
+		return fmt.Errorf("expected , or %q at %q", closing, s.src[s.pos:])
+	}
+	return nil
+}
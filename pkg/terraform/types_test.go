@@ -0,0 +1,197 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	typ, err := ParseType(`list(object({
+    name    = string
+    ports   = optional(list(number), [80, 443])
+    enabled = optional(bool)
+    extra   = tuple([string, map(any)])
+  }))`)
+	if err != nil {
+		t.Fatalf("ParseType failed: %v", err)
+	}
+
+	if typ.Kind != TypeList || typ.Elem.Kind != TypeObject {
+		t.Fatalf("Expected a list of objects, got %+v", typ)
+	}
+	attrs := typ.Elem.Attributes
+	if len(attrs) != 4 {
+		t.Fatalf("Expected four attributes, got %+v", attrs)
+	}
+	if attrs[0].Name != "name" || attrs[0].Optional || attrs[0].Type.Kind != TypeString {
+		t.Errorf("Unexpected name attribute: %+v", attrs[0])
+	}
+	if !attrs[1].Optional || !attrs[1].HasDefault || !reflect.DeepEqual(attrs[1].Default, []interface{}{80.0, 443.0}) {
+		t.Errorf("Unexpected ports attribute: %+v", attrs[1])
+	}
+	if !attrs[2].Optional || attrs[2].HasDefault {
+		t.Errorf("Unexpected enabled attribute: %+v", attrs[2])
+	}
+	if extra := attrs[3].Type; extra.Kind != TypeTuple || len(extra.Elems) != 2 || extra.Elems[1].Elem.Kind != TypeAny {
+		t.Errorf("Unexpected extra attribute: %+v", attrs[3])
+	}
+
+	for _, expr := range []string{`"string"`, "map", ""} {
+		if _, err := ParseType(expr); err != nil {
+			t.Errorf("ParseType(%s) failed: %v", expr, err)
+		}
+	}
+	for _, expr := range []string{"object({...})", "list(string", "strin"} {
+		if _, err := ParseType(expr); err == nil {
+			t.Errorf("Expected ParseType(%s) to fail", expr)
+		}
+	}
+}
+
+func TestDecodeLiteral(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected interface{}
+		ok       bool
+	}{
+		{`"a\nb"`, "a\nb", true},
+		{`"cost: $${price}"`, "cost: ${price}", true},
+		{`"${var.x}"`, nil, false},
+		{"-1.5", -1.5, true},
+		{"null", nil, true},
+		{`["a", 1, true,]`, []interface{}{"a", 1.0, true}, true},
+		{"{\n  a = 1\n  \"b-c\" = { d = [] }\n}", map[string]interface{}{"a": 1.0, "b-c": map[string]interface{}{"d": []interface{}{}}}, true},
+		{"<<-EOT\n    hello\n      world\n    EOT", "hello\n  world\n", true},
+		{"var.x", nil, false},
+		{`["a"][0]`, nil, false},
+		{`upper("a")`, nil, false},
+	}
+
+	for _, test := range tests {
+		got, ok := DecodeLiteral(test.expr)
+		if ok != test.ok || (ok && !reflect.DeepEqual(got, test.expected)) {
+			t.Errorf("DecodeLiteral(%s) = %#v, %v; expected %#v, %v", test.expr, got, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestParseVariablesFromContentDetails(t *testing.T) {
+	content := `
+variable "tier" {
+  type    = string
+  default = "standard"
+
+  validation {
+    condition     = contains(["standard", "premium"], var.tier)
+    error_message = "Unknown tier."
+  }
+}
+
+variable "size" {
+  type = number
+
+  validation {
+    condition     = var.size == 1 || 2 == var.size
+    error_message = "Size must be 1 or 2."
+  }
+}
+
+variable "rules" {
+  type = map(object({
+    port = number
+  }))
+  validation {
+    condition     = length(var.rules) > 0
+    error_message = "At least one rule."
+  }
+}
+`
+	variables, err := ParseVariablesFromContent(content)
+	if err != nil {
+		t.Fatalf("ParseVariablesFromContent failed: %v", err)
+	}
+
+	tier := variables["tier"]
+	if tier.Default != "standard" || !reflect.DeepEqual(tier.Enum, []interface{}{"standard", "premium"}) {
+		t.Errorf("Unexpected tier variable: %+v", tier)
+	}
+	if size := variables["size"]; !reflect.DeepEqual(size.Enum, []interface{}{1.0, 2.0}) {
+		t.Errorf("Unexpected size variable: %+v", size)
+	}
+	rules := variables["rules"]
+	if rules.TypeExpr != "map(object({\n    port = number\n  }))" || rules.Enum != nil {
+		t.Errorf("Unexpected rules variable: %+v", rules)
+	}
+}
+
+func TestParseVariablesFromContentObjectDefaults(t *testing.T) {
+	content := `
+variable "disk" {
+  type    = object({ size = number })
+  default = { size = 1 }
+}
+
+variable "tags" {
+  type    = map(string)
+  default = local.tags
+}
+
+variable "volume" {
+  type = object({ size = number })
+}
+`
+	variables, err := ParseVariablesFromContent(content)
+	if err != nil {
+		t.Fatalf("ParseVariablesFromContent failed: %v", err)
+	}
+
+	if disk := variables["disk"]; disk.Required || !reflect.DeepEqual(disk.Default, map[string]interface{}{"size": 1.0}) {
+		t.Errorf("Expected disk to be optional with its default, got %+v", disk)
+	}
+	if tags := variables["tags"]; tags.Required {
+		t.Errorf("Expected a non-literal default to make tags optional, got %+v", tags)
+	}
+	if volume := variables["volume"]; !volume.Required {
+		t.Errorf("Expected volume to be required, got %+v", volume)
+	}
+}
+
+func TestParseVariablesFromContentGroups(t *testing.T) {
+	content := `
+# The network to deploy into
+# @group networking
+variable "vpc_id" {
+  type = string
+}
+
+variable "subnet_ids" {
+  # @group Networking
+  type = list(string)
+}
+
+# @group storage
+
+variable "name" {
+  description = "[Naming] Name of the service"
+  type        = string
+}
+`
+	variables, err := ParseVariablesFromContent(content)
+	if err != nil {
+		t.Fatalf("ParseVariablesFromContent failed: %v", err)
+	}
+
+	expected := map[string]string{"vpc_id": "networking", "subnet_ids": "Networking", "name": ""}
+	for name, group := range expected {
+		if variables[name].Group != group {
+			t.Errorf("Expected %s in group %q, got %q", name, group, variables[name].Group)
+		}
+	}
+
+	if group := DescriptionGroup(variables["name"].Description); group != "Naming" {
+		t.Errorf("Expected the description prefix to name the group Naming, got %q", group)
+	}
+	if group := DescriptionGroup("Name of the [service]"); group != "" {
+		t.Errorf("Expected no group without a prefix, got %q", group)
+	}
+}
@@ -0,0 +1,164 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metaArguments are the module block arguments that are not module inputs
+var metaArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"providers":  true,
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+}
+
+// CheckModuleCall compares the arguments of a module call with the variables
+// of the called module. It reports arguments the module does not declare,
+// required inputs that are not set and literal values that cannot be converted
+// to the type of their variable.
+func CheckModuleCall(call ModuleCall, variables map[string]Variable) []*FileError {
+	var problems []*FileError
+
+	names := make([]string, 0, len(call.Arguments))
+	for name := range call.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		arg := call.Arguments[name]
+		if metaArguments[name] {
+			continue
+		}
+
+		v, ok := variables[name]
+		if !ok {
+			problems = append(problems, &FileError{
+				File: call.File,
+				Line: arg.Line,
+				Err:  fmt.Errorf("module %q sets %q, which the module does not declare", call.Name, name),
+			})
+			continue
+		}
+
+		if kind := LiteralKind(arg.Expr); !TypeAccepts(v.Type, kind, arg.Expr) {
+			problems = append(problems, &FileError{
+				File: call.File,
+				Line: arg.Line,
+				Err:  fmt.Errorf("module %q sets %q to a %s, which is not compatible with type %s", call.Name, name, kind, v.Type),
+			})
+		}
+	}
+
+	var missing []string
+	for name, v := range variables {
+		if _, ok := call.Arguments[name]; v.Required && !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		problems = append(problems, &FileError{
+			File: call.File,
+			Line: call.Line,
+			Err:  fmt.Errorf("module %q does not set the required input %q", call.Name, name),
+		})
+	}
+
+	return problems
+}
+
+// Kinds of literal expressions returned by LiteralKind
+const (
+	LiteralString = "string"
+	LiteralNumber = "number"
+	LiteralBool   = "bool"
+	LiteralNull   = "null"
+	LiteralList   = "list"
+	LiteralObject = "object"
+)
+
+var numberLiteralRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// LiteralKind classifies a literal HCL expression, returning an empty string
+// for expressions whose value is only known when Terraform evaluates them
+func LiteralKind(expr string) string {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "":
+		return ""
+	case expr == "true" || expr == "false":
+		return LiteralBool
+	case expr == "null":
+		return LiteralNull
+	case numberLiteralRegex.MatchString(expr):
+		return LiteralNumber
+	case strings.HasPrefix(expr, "<<"):
+		return LiteralString
+	}
+
+	// Only a single literal counts; "a" == "b" or [1][0] are expressions
+	kinds := map[byte]string{'"': LiteralString, '[': LiteralList, '{': LiteralObject}
+	if kind, ok := kinds[expr[0]]; ok && literalEnd(expr) == len(expr) {
+		return kind
+	}
+	return ""
+}
+
+// literalEnd returns the length of the string, list or object literal at the
+// start of expr, or -1 if it is not terminated
+func literalEnd(expr string) int {
+	s := &scanner{src: expr}
+	if expr[0] == '"' {
+		s.readString()
+		return s.pos
+	}
+
+	closing := map[byte]byte{'[': ']', '{': '}'}[expr[0]]
+	s.advance()
+	if !s.skipBalanced(closing) {
+		return -1
+	}
+	return s.pos
+}
+
+// TypeAccepts reports whether a literal of the given kind can be converted to
+// typeStr. Unknown types and non-literal expressions are always accepted.
+func TypeAccepts(typeStr string, kind string, expr string) bool {
+	if kind == "" || kind == LiteralNull {
+		return true
+	}
+
+	base := strings.TrimSpace(typeStr)
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+	value, _ := UnquoteString(strings.TrimSpace(expr))
+
+	switch base {
+	case "string":
+		return kind != LiteralList && kind != LiteralObject
+	case "number":
+		if kind == LiteralString {
+			_, err := strconv.ParseFloat(value, 64)
+			return err == nil || strings.Contains(expr, "${")
+		}
+		return kind == LiteralNumber
+	case "bool":
+		if kind == LiteralString {
+			return value == "true" || value == "false" || strings.Contains(expr, "${")
+		}
+		return kind == LiteralBool
+	case "list", "set", "tuple":
+		return kind == LiteralList
+	case "map", "object":
+		return kind == LiteralObject
+	}
+	return true
+}
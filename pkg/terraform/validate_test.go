@@ -0,0 +1,107 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckModuleCall(t *testing.T) {
+	variables := map[string]Variable{
+		"name":    {Name: "name", Type: "string", Required: true},
+		"region":  {Name: "region", Type: "string", Required: true},
+		"size":    {Name: "size", Type: "number"},
+		"enabled": {Name: "enabled", Type: "bool"},
+		"tags":    {Name: "tags", Type: "map(string)"},
+	}
+	call := ModuleCall{
+		Name: "app",
+		File: "examples/basic/main.tf",
+		Line: 1,
+		Arguments: map[string]Attribute{
+			"source":  {Name: "source", Expr: `"../.."`, Line: 2},
+			"count":   {Name: "count", Expr: "1", Line: 3},
+			"name":    {Name: "name", Expr: "var.name", Line: 4},
+			"size":    {Name: "size", Expr: `"large"`, Line: 5},
+			"enabled": {Name: "enabled", Expr: `"true"`, Line: 6},
+			"tags":    {Name: "tags", Expr: `["a"]`, Line: 7},
+			"colour":  {Name: "colour", Expr: `"red"`, Line: 8},
+		},
+	}
+
+	problems := CheckModuleCall(call, variables)
+
+	expected := []struct {
+		line    int
+		message string
+	}{
+		{8, `sets "colour", which the module does not declare`},
+		{5, `sets "size" to a string`},
+		{7, `sets "tags" to a list`},
+		{1, `does not set the required input "region"`},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, e := range expected {
+		if problems[i].Line != e.line || !strings.Contains(problems[i].Err.Error(), e.message) {
+			t.Errorf("Problem %d: expected line %d and %q, got line %d and %q", i, e.line, e.message, problems[i].Line, problems[i].Err)
+		}
+	}
+}
+
+func TestLiteralKind(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`"text"`, LiteralString},
+		{"<<EOT\ntext\nEOT", LiteralString},
+		{"42", LiteralNumber},
+		{"-1.5e3", LiteralNumber},
+		{"true", LiteralBool},
+		{"null", LiteralNull},
+		{`["a", "b"]`, LiteralList},
+		{`{ a = "b" }`, LiteralObject},
+		{"var.name", ""},
+		{`"a" == "b"`, ""},
+		{"[1][0]", ""},
+		{`{ a = 1 }.a`, ""},
+	}
+
+	for _, test := range tests {
+		if got := LiteralKind(test.expr); got != test.expected {
+			t.Errorf("LiteralKind(%s) = %q; expected %q", test.expr, got, test.expected)
+		}
+	}
+}
+
+func TestTypeAccepts(t *testing.T) {
+	tests := []struct {
+		typeStr  string
+		expr     string
+		expected bool
+	}{
+		{"string", `"text"`, true},
+		{"string", "42", true},
+		{"string", `["a"]`, false},
+		{"number", `"42"`, true},
+		{"number", `"${var.size}"`, true},
+		{"number", `"large"`, false},
+		{"bool", `"false"`, true},
+		{"bool", "1", false},
+		{"list(string)", `["a"]`, true},
+		{"set(string)", `{}`, false},
+		{"map(string)", `{}`, true},
+		{"object({ a = string })", `["a"]`, false},
+		{"any", `["a"]`, true},
+		{"", "42", true},
+		{"number", "null", true},
+		{"number", "var.size", true},
+	}
+
+	for _, test := range tests {
+		if got := TypeAccepts(test.typeStr, LiteralKind(test.expr), test.expr); got != test.expected {
+			t.Errorf("TypeAccepts(%s, %s) = %v; expected %v", test.typeStr, test.expr, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"regexp"
+	"strings"
+)
+
+// describeVariable fills in the complete type constraint, the literal default
+// value and the accepted values of a variable from its block. A variable with
+// any default is optional, even when the default is not a literal.
+func describeVariable(v *Variable, block Block) {
+	attrs, blocks := ParseBody(block.Body)
+
+	for _, attr := range attrs {
+		switch attr.Name {
+		case "type":
+			v.TypeExpr = strings.TrimSpace(attr.Expr)
+		case "default":
+			v.Required = false
+			if value, ok := DecodeLiteral(attr.Expr); ok {
+				v.Default = value
+			}
+		}
+	}
+
+	for _, nested := range blocks {
+		if nested.Type != "validation" {
+			continue
+		}
+		if condition, ok := AttributeMap(nested.Body)["condition"]; ok {
+			if enum := validationEnum(v.Name, condition.Expr); enum != nil {
+				v.Enum = enum
+			}
+		}
+	}
+}
+
+var (
+	groupCommentRegex     = regexp.MustCompile(`^\s*(?:#|//)\s*@group\s+(.+?)\s*$`)
+	descriptionGroupRegex = regexp.MustCompile(`^\s*\[([^\]]+)\]`)
+)
+
+// commentGroup returns the group named by a "# @group networking" comment in
+// the body of a block or in the comment lines directly above it
+func commentGroup(content string, block Block) string {
+	for _, line := range strings.Split(block.Body, "\n") {
+		if match := groupCommentRegex.FindStringSubmatch(line); match != nil {
+			return match[1]
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := block.Line - 2; i >= 0 && i < len(lines); i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
+			break
+		}
+		if match := groupCommentRegex.FindStringSubmatch(line); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// DescriptionGroup returns the group named by a description prefix such as
+// "[Networking] The VPC to deploy into", or an empty string
+func DescriptionGroup(description string) string {
+	if match := descriptionGroupRegex.FindStringSubmatch(description); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
+// validationEnum returns the values a validation condition restricts the
+// variable to, for conditions of the forms
+//
+//	contains(["a", "b"], var.name)
+//	var.name == "a" || var.name == "b"
+//
+// and nil for any other condition
+func validationEnum(name string, condition string) []interface{} {
+	condition = strings.TrimSpace(NormalizeWhitespace(condition))
+	reference := regexp.QuoteMeta("var." + name)
+
+	contains := regexp.MustCompile(`^contains\(\s*(\[.*\])\s*,\s*` + reference + `\s*\)$`)
+	if match := contains.FindStringSubmatch(condition); match != nil {
+		if values, ok := DecodeLiteral(match[1]); ok {
+			if list := values.([]interface{}); len(list) > 0 {
+				return list
+			}
+		}
+		return nil
+	}
+
+	equals := regexp.MustCompile(`^(?:` + reference + `\s*==\s*(.+)|(.+?)\s*==\s*` + reference + `)$`)
+	var values []interface{}
+	for _, part := range strings.Split(condition, "||") {
+		match := equals.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil
+		}
+		value, ok := DecodeLiteral(match[1] + match[2])
+		if !ok {
+			return nil
+		}
+		values = append(values, value)
+	}
+	return values
+}